@@ -2,42 +2,53 @@ package tgx
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"time"
 )
 
-var client = &http.Client{
-	Timeout: 30 * time.Second,
-}
-
 type TelegramResponse struct {
-	Ok          bool            `json:"ok"`
-	Result      json.RawMessage `json:"result"`
-	Description string          `json:"description"`
-	ErrorCode   int             `json:"error_code"`
+	Ok          bool                `json:"ok"`
+	Result      json.RawMessage     `json:"result"`
+	Description string              `json:"description"`
+	ErrorCode   int                 `json:"error_code"`
+	Parameters  *ResponseParameters `json:"parameters,omitempty"`
 }
 
 func (ctx *Context) makeRequest(method string, params map[string]interface{}) error {
-	return makeAPIRequest(ctx.bot.token, method, params)
+	return ctx.bot.makeAPIRequest(ctx.Context(), method, params)
 }
 func (ctx *CallbackContext) makeRequest(method string, params map[string]interface{}) error {
-	return makeAPIRequest(ctx.bot.token, method, params)
+	return ctx.bot.makeAPIRequest(ctx.Context(), method, params)
 }
 
-func makeAPIRequest(token, method string, params map[string]interface{}) error {
-	_, err := makeAPIRequestWithResult(token, method, params)
-	return err
+// methodURL returns the endpoint for a Bot API method on the configured server.
+func (b *Bot) methodURL(method string) string {
+	return fmt.Sprintf("%s/bot%s/%s", b.apiURL, b.token, method)
+}
+
+// fileURL returns the download endpoint for a file_path returned by getFile.
+func (b *Bot) fileURL(filePath string) string {
+	return fmt.Sprintf("%s/bot%s/%s", b.fileBaseURL, b.token, filePath)
 }
 
-func makeAPIRequestWithResult(token, method string, params map[string]interface{}) (json.RawMessage, error) {
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/%s", token, method)
+// withTimeout is context.WithTimeout that treats a non-positive timeout as none.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
 
+func (b *Bot) makeAPIRequest(ctx context.Context, method string, params map[string]interface{}) error {
+	_, err := b.makeAPIRequestWithResult(ctx, method, params)
+	return err
+}
+
+func (b *Bot) makeAPIRequestWithResult(ctx context.Context, method string, params map[string]interface{}) (json.RawMessage, error) {
 	body, err := json.Marshal(params)
 	if err != nil {
 		return nil, &BotError{
@@ -47,8 +58,105 @@ func makeAPIRequestWithResult(token, method string, params map[string]interface{
 		}
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	return b.execute(ctx, method, params, false, func() (*requestBody, error) {
+		return &requestBody{
+			reader:      io.NopCloser(bytes.NewReader(body)),
+			contentType: "application/json",
+			size:        int64(len(body)),
+		}, nil
+	})
+}
+
+// decodeResult unmarshals the result of a successful call into a T.
+// It is meant to wrap a call directly: decodeResult[T](b.makeAPIRequestWithResult(...)).
+func decodeResult[T any](result json.RawMessage, err error) (T, error) {
+	var v T
+	if err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(result, &v); err != nil {
+		return v, &BotError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to decode result",
+			Err:     err,
+		}
+	}
+	return v, nil
+}
+
+// requestBody is the payload of a single HTTP attempt.
+type requestBody struct {
+	reader      io.ReadCloser
+	contentType string
+	size        int64 // -1 when unknown
+}
+
+// bodyFunc returns a fresh request body. It is called once per attempt so
+// that retries never reuse a drained reader.
+type bodyFunc func() (*requestBody, error)
+
+// execute sends a request, queueing it behind the bot's rate limits and
+// retrying it according to the bot's RetryPolicy. params are only inspected
+// for chat_id and allow_paid_broadcast; the body comes from newBody.
+func (b *Bot) execute(ctx context.Context, method string, params map[string]interface{}, upload bool, newBody bodyFunc) (json.RawMessage, error) {
+	b.logger.Debug("Calling %s", method)
+	b.outbound.add()
+	defer b.outbound.done()
+
+	for attempt := 0; ; attempt++ {
+		if err := b.limiter.wait(ctx, method, params); err != nil {
+			return nil, &BotError{
+				Code:    http.StatusRequestTimeout,
+				Message: "Gave up waiting for rate limit",
+				Err:     err,
+			}
+		}
+
+		result, err := b.send(ctx, method, upload, newBody)
+		if err == nil {
+			return result, nil
+		}
+
+		delay, ok := b.retryPolicy.backoff(attempt, err)
+		if !ok || ctx.Err() != nil {
+			return nil, err
+		}
+
+		b.logger.Warn("Retrying %s in %v (attempt %d): %v", method, delay, attempt+1, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// send performs a single HTTP round trip and decodes the Telegram envelope.
+func (b *Bot) send(ctx context.Context, method string, upload bool, newBody bodyFunc) (json.RawMessage, error) {
+	url := b.methodURL(method)
+
+	body, err := newBody()
+	if err != nil {
+		return nil, &BotError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to build request body",
+			Err:     err,
+		}
+	}
+
+	timeout := b.requestTimeout(method, upload)
+	if timeout > 0 {
+		timeout += longPollTimeout(ctx)
+	}
+	reqCtx, cancel := withTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, "POST", url, body.reader)
 	if err != nil {
+		body.reader.Close()
 		return nil, &BotError{
 			Code:    http.StatusInternalServerError,
 			Message: "Failed to create request",
@@ -56,10 +164,11 @@ func makeAPIRequestWithResult(token, method string, params map[string]interface{
 		}
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	req.ContentLength = body.size
+	req.Header.Set("Content-Type", body.contentType)
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := client.Do(req)
+	resp, err := b.client.Do(req)
 	if err != nil {
 		return nil, &BotError{
 			Code:    http.StatusServiceUnavailable,
@@ -80,8 +189,12 @@ func makeAPIRequestWithResult(token, method string, params map[string]interface{
 
 	var telegramResp TelegramResponse
 	if err = json.Unmarshal(respBody, &telegramResp); err != nil {
+		code := http.StatusInternalServerError
+		if resp.StatusCode >= 500 {
+			code = resp.StatusCode
+		}
 		return nil, &BotError{
-			Code:    http.StatusInternalServerError,
+			Code:    code,
 			Message: "Failed to parse response",
 			Err:     err,
 		}
@@ -92,6 +205,9 @@ func makeAPIRequestWithResult(token, method string, params map[string]interface{
 			Code:        telegramResp.ErrorCode,
 			Description: telegramResp.Description,
 		}
+		if telegramResp.Parameters != nil {
+			apiError.Parameters = *telegramResp.Parameters
+		}
 
 		switch apiError.Code {
 		case 429:
@@ -130,159 +246,54 @@ func makeAPIRequestWithResult(token, method string, params map[string]interface{
 	return telegramResp.Result, nil
 }
 
-func makeMultipartReq(token, method string, params map[string]interface{}, paramName, path string) error {
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/%s", token, method)
-
-	fmt.Println("FilePath: ", path)
-	fmt.Println("FileName: ", filepath.Base(path))
-	file, err := os.Open(path)
-	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
-	}
-	defer file.Close()
-
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	part, err := writer.CreateFormFile(paramName, filepath.Base(path))
-	if err != nil {
-		return fmt.Errorf("failed to create form file: %w", err)
-	}
-
-	_, err = io.Copy(part, file)
-	if err != nil {
-		return fmt.Errorf("failed to write file to form: %w", err)
-	}
-
-	for key, val := range params {
-		strVal := fmt.Sprintf("%v", val)
-		err = writer.WriteField(key, strVal)
-		if err != nil {
-			return fmt.Errorf("failed to write form field %q: %w", key, err)
-		}
-	}
-
-	err = writer.Close()
-	if err != nil {
-		return fmt.Errorf("failed to close writer: %w", err)
-	}
-
-	req, err := http.NewRequest(http.MethodPost, url, body)
-	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	fmt.Println(string(respBody))
+// makeRequestWithFiles sends params as JSON, or as multipart/form-data when
+// any of them is an *InputFile or io.Reader with content to upload.
+func (b *Bot) makeRequestWithFiles(ctx context.Context, method string, params map[string]interface{}) (json.RawMessage, error) {
+	fields, files := splitFiles(params)
+	return b.makeUploadRequest(ctx, method, fields, files)
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("unexpected response status: %d, body: %s", resp.StatusCode, string(respBody))
+// makeUploadRequest sends fields along with files. Without files it is a plain JSON request.
+func (b *Bot) makeUploadRequest(ctx context.Context, method string, fields map[string]interface{}, files map[string]*InputFile) (json.RawMessage, error) {
+	if len(files) == 0 {
+		return b.makeAPIRequestWithResult(ctx, method, fields)
 	}
-
-	return nil
+	return b.makeMultipartReq(ctx, method, fields, files)
 }
 
-func makeMultipartMediaGroupReq(token, method string, mediaGroup *SendMediaGroupRequest, files []MediaFile) error {
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/%s", token, method)
+// splitFiles separates uploads from ordinary parameters. Files Telegram can
+// fetch by itself are replaced by their file_id or URL.
+func splitFiles(params map[string]interface{}) (map[string]interface{}, map[string]*InputFile) {
+	fields := make(map[string]interface{}, len(params))
+	var files map[string]*InputFile
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	attachments := make(map[string]string)
-	for i, file := range files {
-		if file.FilePath != "" {
-			f, err := os.Open(file.FilePath)
-			if err != nil {
-				return fmt.Errorf("failed to open file %s: %w", file.FilePath, err)
-			}
-			defer f.Close()
-
-			attachmentKey := fmt.Sprintf("file%d", i)
-			attachments[attachmentKey] = filepath.Base(file.FilePath)
-
-			mediaGroup.Media[i].Media = "attach://" + attachmentKey
-
-			part, err := writer.CreateFormFile(attachmentKey, filepath.Base(file.FilePath))
-			if err != nil {
-				return fmt.Errorf("failed to create form file: %w", err)
-			}
-
-			if _, err := io.Copy(part, f); err != nil {
-				return fmt.Errorf("failed to copy file content: %w", err)
+	for key, val := range params {
+		var file *InputFile
+		switch v := val.(type) {
+		case *InputFile:
+			if v == nil {
+				continue
 			}
+			file = v
+		case InputFile:
+			file = &v
+		case io.Reader:
+			file = &InputFile{Reader: v}
+		default:
+			fields[key] = val
+			continue
 		}
-	}
-
-	mediaBytes, err := json.Marshal(mediaGroup.Media)
-	if err != nil {
-		return fmt.Errorf("failed to marshal media group: %w", err)
-	}
-
-	fields := map[string]string{
-		"chat_id": fmt.Sprintf("%d", mediaGroup.ChatID),
-		"media":   string(mediaBytes),
-	}
-
-	if mediaGroup.DisableNotification {
-		fields["disable_notification"] = "true"
-	}
 
-	if mediaGroup.ProtectContent {
-		fields["protect_content"] = "true"
-	}
-
-	if mediaGroup.ReplyParams != nil {
-		replyBytes, err := json.Marshal(mediaGroup.ReplyParams)
-		if err != nil {
-			return fmt.Errorf("failed to marshal reply parameters: %w", err)
+		if !file.isUpload() {
+			fields[key] = file.reference()
+			continue
 		}
-		fields["reply_parameters"] = string(replyBytes)
-	}
-
-	for key, value := range fields {
-		if err := writer.WriteField(key, value); err != nil {
-			return fmt.Errorf("failed to write field %s: %w", key, err)
+		if files == nil {
+			files = make(map[string]*InputFile)
 		}
+		files[key] = file
 	}
-
-	if err := writer.Close(); err != nil {
-		return fmt.Errorf("failed to close writer: %w", err)
-	}
-
-	req, err := http.NewRequest(http.MethodPost, url, body)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
-	}
-
-	return nil
+	return fields, files
 }
 
 func IsAPIError(err error, errCode int) bool {
@@ -1,11 +1,17 @@
 package tgx
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/harshyadavone/tgx/models"
 	"github.com/harshyadavone/tgx/pkg/logger"
@@ -16,18 +22,63 @@ const (
 	ParseModeHTML     = "HTML"
 )
 
+// secretTokenHeader carries the secret_token given to setWebhook on every webhook request.
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
 type ErrorHandler func(ctx *Context, err error)
 type Handler func(ctx *Context) error
 type callbackHandler func(ctx *CallbackContext) error
 
+// Bot is a Telegram bot client. Every API method has a ...Ctx variant that
+// takes a context.Context; the plain form calls it with context.Background().
 type Bot struct {
-	token      string
-	webhookURL string
-
-	messageHandlers  map[string]Handler
-	commandHandler   map[string]Handler
-	callbackHandlers map[string]callbackHandler
-	errorHandler     ErrorHandler
+	token       string
+	webhookURL  string
+	secretToken string
+
+	apiURL      string
+	fileBaseURL string
+
+	client         *http.Client
+	transport      http.RoundTripper
+	proxy          func(*http.Request) (*url.URL, error)
+	timeout        time.Duration
+	uploadTimeout  time.Duration
+	methodTimeouts map[string]time.Duration
+	handlerTimeout time.Duration
+	retryPolicy    *RetryPolicy
+	limiter        *rateLimiter
+	pool           WorkerPool
+	orderKey       UpdateKey
+	dispatcher     *dispatcher
+
+	// done is cancelled by Shutdown; pollers and outbound track what it waits for.
+	done     context.Context
+	stop     context.CancelFunc
+	pollers  tracker
+	outbound tracker
+
+	updateLog  *UpdateLog
+	replayOnce sync.Once
+
+	maxDownloadSize int64
+
+	messages                  messageRoutes
+	editedMessages            messageRoutes
+	channelPosts              messageRoutes
+	editedChannelPosts        messageRoutes
+	callbackHandlers          map[string]callbackHandler
+	callbackRoutes            []callbackRoute
+	unknownCallbackHandler    callbackHandler
+	inlineQueryHandler        InlineQueryHandler
+	chosenInlineResultHandler ChosenInlineResultHandler
+	myChatMemberHandler       ChatMemberHandler
+	chatMemberHandler         ChatMemberHandler
+	errorHandler              ErrorHandler
+	middleware                []Middleware
+
+	usernameMu sync.Mutex
+	username   string // learned with GetMe to recognise /command@username
 
 	logger logger.Logger
 }
@@ -44,16 +95,40 @@ type WebhookInfo struct {
 	AllowedUpdates               []string `json:"allowed_updates,omitempty"`
 }
 
-func NewBot(token, webhookURL string, logger logger.Logger) *Bot {
-	return &Bot{
-		token:            token,
-		webhookURL:       webhookURL,
-		messageHandlers:  make(map[string]Handler),
-		commandHandler:   make(map[string]Handler),
-		callbackHandlers: make(map[string]callbackHandler),
-		logger:           logger,
-		errorHandler:     defaultErrorHandler,
+func NewBot(token, webhookURL string, logger logger.Logger, opts ...Option) *Bot {
+	b := &Bot{
+		token:              token,
+		webhookURL:         webhookURL,
+		apiURL:             DefaultAPIURL,
+		timeout:            DefaultTimeout,
+		uploadTimeout:      DefaultUploadTimeout,
+		limiter:            newRateLimiter(*DefaultRateLimits()),
+		maxDownloadSize:    DefaultMaxDownloadSize,
+		messages:           newMessageRoutes(),
+		editedMessages:     newMessageRoutes(),
+		channelPosts:       newMessageRoutes(),
+		editedChannelPosts: newMessageRoutes(),
+		callbackHandlers:   make(map[string]callbackHandler),
+		logger:             logger,
+		errorHandler:       defaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	if b.fileBaseURL == "" {
+		b.fileBaseURL = b.apiURL + "/file"
 	}
+
+	if b.client == nil {
+		b.client = b.newHTTPClient()
+	}
+
+	b.dispatcher = newDispatcher(b, b.pool, b.orderKey)
+	b.done, b.stop = context.WithCancel(context.Background())
+
+	return b
 }
 
 func defaultErrorHandler(ctx *Context, err error) {
@@ -66,17 +141,66 @@ func defaultErrorHandler(ctx *Context, err error) {
 }
 
 func (b *Bot) SetWebhook() error {
-	return makeAPIRequest(b.token, "setWebhook", map[string]interface{}{
-		"url": b.webhookURL,
-	})
+	return b.SetWebhookCtx(context.Background())
+}
+
+func (b *Bot) SetWebhookCtx(ctx context.Context) error {
+	return b.SetWebhookWithOptsCtx(ctx, &SetWebhookRequest{})
+}
+
+// SetWebhookWithOpts registers the webhook with extra settings. URL defaults
+// to the one the bot was created with. The secret token always comes from
+// WithSecretToken, so that HandleWebhook checks the one Telegram sends.
+func (b *Bot) SetWebhookWithOpts(req *SetWebhookRequest) error {
+	return b.SetWebhookWithOptsCtx(context.Background(), req)
+}
+
+func (b *Bot) SetWebhookWithOptsCtx(ctx context.Context, req *SetWebhookRequest) error {
+	url := req.URL
+	if url == "" {
+		url = b.webhookURL
+	}
+	// Built by hand rather than with ParamBuilder, which would log the secret token.
+	params := map[string]interface{}{
+		"url": url,
+	}
+	if req.Certificate != nil {
+		params["certificate"] = req.Certificate
+	}
+	if req.IPAddress != "" {
+		params["ip_address"] = req.IPAddress
+	}
+	if req.MaxConnections > 0 {
+		params["max_connections"] = req.MaxConnections
+	}
+	if req.AllowedUpdates != nil {
+		params["allowed_updates"] = req.AllowedUpdates
+	}
+	if req.DropPendingUpdates {
+		params["drop_pending_updates"] = true
+	}
+	if b.secretToken != "" {
+		params["secret_token"] = b.secretToken
+	}
+
+	_, err := b.makeRequestWithFiles(ctx, "setWebhook", params)
+	return err
 }
 
 func (b *Bot) DeleteWebhook() error {
-	return makeAPIRequest(b.token, "deleteWebhook", map[string]interface{}{})
+	return b.DeleteWebhookCtx(context.Background())
+}
+
+func (b *Bot) DeleteWebhookCtx(ctx context.Context) error {
+	return b.makeAPIRequest(ctx, "deleteWebhook", map[string]interface{}{})
 }
 
 func (b *Bot) GetWebhookInfo() (*WebhookInfo, error) {
-	result, err := makeAPIRequestWithResult(b.token, "getWebhookInfo", nil)
+	return b.GetWebhookInfoCtx(context.Background())
+}
+
+func (b *Bot) GetWebhookInfoCtx(ctx context.Context) (*WebhookInfo, error) {
+	result, err := b.makeAPIRequestWithResult(ctx, "getWebhookInfo", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -94,7 +218,11 @@ func (b *Bot) GetWebhookInfo() (*WebhookInfo, error) {
 }
 
 func (b *Bot) GetMe() (*models.User, error) {
-	result, err := makeAPIRequestWithResult(b.token, "getMe", nil)
+	return b.GetMeCtx(context.Background())
+}
+
+func (b *Bot) GetMeCtx(ctx context.Context) (*models.User, error) {
+	result, err := b.makeAPIRequestWithResult(ctx, "getMe", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -111,12 +239,12 @@ func (b *Bot) GetMe() (*models.User, error) {
 	return &user, nil
 }
 
-func (b *Bot) logOut() error {
-	return makeAPIRequest(b.token, "getMe", nil)
+func (b *Bot) logOut(ctx context.Context) error {
+	return b.makeAPIRequest(ctx, "getMe", nil)
 }
 
-func (b *Bot) close() error {
-	return makeAPIRequest(b.token, "close", nil)
+func (b *Bot) close(ctx context.Context) error {
+	return b.makeAPIRequest(ctx, "close", nil)
 }
 
 func (b *Bot) HandleWebhook(w http.ResponseWriter, r *http.Request) {
@@ -126,7 +254,21 @@ func (b *Bot) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if b.secretToken != "" {
+		got := r.Header.Get(secretTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(got), []byte(b.secretToken)) != 1 {
+			b.logger.Warn("Rejected webhook request with invalid secret token from %s", r.RemoteAddr)
+			http.Error(w, "Invalid secret token", http.StatusUnauthorized)
+			return
+		}
+	}
+
 	body, err := io.ReadAll(r.Body)
+	if maxErr := (*http.MaxBytesError)(nil); errors.As(err, &maxErr) {
+		b.logger.Warn("Rejected webhook request larger than %d bytes", maxErr.Limit)
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
 	if err != nil {
 		b.logger.Error("Code : %d,\nMessage: %s,\nErr: %v", http.StatusBadRequest, "Failed to read request body", err)
 		http.Error(w, "Failed to read request body", http.StatusBadRequest)
@@ -141,30 +283,91 @@ func (b *Bot) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				b.logger.Error("Panic recovered in update handler: %v", r)
-			}
-		}()
+	b.ReplayUpdates()
 
-		if update.Message != nil {
-			if err := b.handleMessageUpdate(update.Message); err != nil {
-				b.logger.Error("Error handling message update: %v", err)
-			}
-		} else if update.CallbackQuery != nil {
-			if err := b.handleCallbackQuery(update.CallbackQuery); err != nil {
-				b.logger.Error("Error handling callback query: %v", err)
-			}
-		} else {
-			b.logger.Warn("Received update with no message or callback query")
+	if err := b.dispatcher.dispatch(r.Context(), &update, false); err != nil {
+		if errors.Is(err, errQueueFull) && b.pool.Policy == QueueDrop {
+			b.logger.Warn("Dropped update %d: %v", update.UpdateId, err)
+			w.WriteHeader(http.StatusOK)
+			return
 		}
-	}()
+		b.logger.Warn("Rejected update %d: %v", update.UpdateId, err)
+		http.Error(w, "Update not accepted, try again later", http.StatusServiceUnavailable)
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
-func (b *Bot) handleMessageUpdate(message *models.Message) error {
+var (
+	// errHandlerPanic wraps the value of a panic recovered from a handler.
+	errHandlerPanic = errors.New("update handler panic")
+	// errNoHandler is wrapped by the errors of messages no handler was
+	// registered for. Handling them again would fail the same way.
+	errNoHandler = errors.New("no handler")
+)
+
+// processUpdate routes an update to its handlers under the handler deadline.
+// It is the dispatch path shared by HandleWebhook and StartPolling. It returns
+// the error of the handler, or one wrapping errHandlerPanic if it panicked.
+func (b *Bot) processUpdate(ctx context.Context, update *models.Update) (err error) {
+	ctx, cancel := withTimeout(ctx, b.handlerTimeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error("Panic recovered in update handler: %v", r)
+			err = fmt.Errorf("%w: %v", errHandlerPanic, r)
+		}
+	}()
+
+	base := handlerContext{bot: b, reqCtx: ctx, update: update}
+
+	switch {
+	case update.Message != nil:
+		if err = b.handleMessageUpdate(base, update.Message, &b.messages); err != nil {
+			b.logger.Error("Error handling message update: %v", err)
+		}
+	case update.EditedMessage != nil:
+		if err = b.handleMessageUpdate(base, update.EditedMessage, &b.editedMessages); err != nil {
+			b.logger.Error("Error handling edited message update: %v", err)
+		}
+	case update.ChannelPost != nil:
+		if err = b.handleMessageUpdate(base, update.ChannelPost, &b.channelPosts); err != nil {
+			b.logger.Error("Error handling channel post update: %v", err)
+		}
+	case update.EditedChannelPost != nil:
+		if err = b.handleMessageUpdate(base, update.EditedChannelPost, &b.editedChannelPosts); err != nil {
+			b.logger.Error("Error handling edited channel post update: %v", err)
+		}
+	case update.CallbackQuery != nil:
+		if err = b.handleCallbackQuery(base, update.CallbackQuery); err != nil {
+			b.logger.Error("Error handling callback query: %v", err)
+		}
+	case update.InlineQuery != nil:
+		if err = b.handleInlineQuery(base, update.InlineQuery); err != nil {
+			b.logger.Error("Error handling inline query: %v", err)
+		}
+	case update.ChosenInlineResult != nil:
+		if err = b.handleChosenInlineResult(base, update.ChosenInlineResult); err != nil {
+			b.logger.Error("Error handling chosen inline result: %v", err)
+		}
+	case update.MyChatMember != nil:
+		if err = b.handleChatMember(base, update.MyChatMember, b.myChatMemberHandler); err != nil {
+			b.logger.Error("Error handling my_chat_member update: %v", err)
+		}
+	case update.ChatMember != nil:
+		if err = b.handleChatMember(base, update.ChatMember, b.chatMemberHandler); err != nil {
+			b.logger.Error("Error handling chat_member update: %v", err)
+		}
+	default:
+		b.logger.Warn("Received update %d of a kind that has no handlers", update.UpdateId)
+	}
+	return err
+}
+
+// handleMessageUpdate routes a message to the command or message type
+// handlers in routes, which depend on the kind of update it arrived in.
+func (b *Bot) handleMessageUpdate(base handlerContext, message *models.Message, routes *messageRoutes) (err error) {
 
 	if message == nil {
 		return &BotError{
@@ -176,106 +379,104 @@ func (b *Bot) handleMessageUpdate(message *models.Message) error {
 	defer func() {
 		if r := recover(); r != nil {
 			b.logger.Error("Panic recovered in handleMessageUpdate: %v", r)
+			err = fmt.Errorf("%w: %v", errHandlerPanic, r)
 		}
 	}()
 
 	ctx := &Context{
-		Text:      message.Text,
-		UserID:    message.From.Id,
-		Username:  message.From.Username,
-		MessageId: message.MessageId,
-		ChatID:    message.Chat.Id,
-		bot:       b,
-	}
-
-	if strings.HasPrefix(message.Text, "/") {
-
-		parts := strings.Split(message.Text, " ")
-		if len(parts) < 1 {
-			b.logger.Error("Not a valid command")
-			return &BotError{
-				Code:    http.StatusInternalServerError,
-				Message: "Failed to parse command",
-				Err:     fmt.Errorf("Failed to parse command"),
+		Text:           message.Text,
+		UserID:         message.From.Id,
+		Username:       message.From.Username,
+		MessageId:      message.MessageId,
+		ChatID:         message.Chat.Id,
+		Message:        message,
+		handlerContext: base,
+	}
+
+	if command, mention, rawArgs, ok := parseCommand(message.Text); ok {
+		if mention != "" {
+			username, err := b.botUsername(base.Context())
+			if err != nil {
+				return &BotError{
+					Code:    http.StatusInternalServerError,
+					Message: "Failed to learn the bot's username",
+					Err:     err,
+				}
 			}
-		}
-
-		command := strings.Split(parts[0], "/")
-		if len(command) <= 1 {
-			b.logger.Error("Not a valid command")
-			return &BotError{
-				Code:    http.StatusInternalServerError,
-				Message: "Not a valid command",
-				Err:     fmt.Errorf("Failed to split parts"),
+			if !strings.EqualFold(mention, username) {
+				b.logger.Debug("Ignoring command /%s addressed to @%s", command, mention)
+				return nil
 			}
 		}
 
-		if len(parts) > 1 {
-			args := parts[1:]
-			ctx.Args = args
-		}
+		ctx.Command = command
+		ctx.RawArgs = rawArgs
+		ctx.Args = splitArgs(rawArgs)
 
 		b.logger.Debug("Received message: %s", message.Text)
-		b.logger.Debug("Parsed command: %s", command[1])
+		b.logger.Debug("Parsed command: %s", command)
 		if len(ctx.Args) > 0 {
 			b.logger.Debug("Arguments: [%s]", strings.Join(ctx.Args, ", "))
 		}
 
-		if handler, ok := b.commandHandler[command[1]]; ok {
-			b.logger.Info("Executing command: %s", command[1])
-
+		if handler, ok := routes.commands[strings.ToLower(command)]; ok {
+			b.logger.Info("Executing command: %s", command)
 			return b.safeExecute(ctx, handler)
-		} else {
-			return &BotError{
-				Code:    http.StatusNotFound,
-				Message: "Unknown command",
-				Err:     fmt.Errorf("command '%s' not found", command[1]),
-			}
 		}
-
+		if routes.unknownCommand != nil {
+			return b.safeExecute(ctx, routes.unknownCommand)
+		}
+		return &BotError{
+			Code:    http.StatusNotFound,
+			Message: "Unknown command",
+			Err:     fmt.Errorf("%w for command '%s'", errNoHandler, command),
+		}
 	}
 	switch {
 	case message.Text != "":
-		if handler, ok := b.messageHandlers["Text"]; ok {
+		if handler := routes.matchText(ctx); handler != nil {
+			return b.safeExecute(ctx, handler)
+		}
+		if handler, ok := routes.types["Text"]; ok {
 			return b.safeExecute(ctx, handler)
 		}
 	case message.Photo != nil:
-		if handler, ok := b.messageHandlers["Photo"]; ok {
+		if handler, ok := routes.types["Photo"]; ok {
 			ctx.Photo = message.Photo
 			return b.safeExecute(ctx, handler)
 		}
 	case message.Video != nil:
-		if handler, ok := b.messageHandlers["Video"]; ok {
+		if handler, ok := routes.types["Video"]; ok {
 			ctx.Video = message.Video
 			return b.safeExecute(ctx, handler)
 		}
 	case message.Voice != nil:
-		if handler, ok := b.messageHandlers["Voice"]; ok {
+		if handler, ok := routes.types["Voice"]; ok {
 			ctx.Voice = message.Voice
 			return b.safeExecute(ctx, handler)
 		}
 	case message.Document != nil:
-		if handler, ok := b.messageHandlers["Document"]; ok {
+		if handler, ok := routes.types["Document"]; ok {
 			ctx.Document = message.Document
 			return b.safeExecute(ctx, handler)
 		}
 	case message.Animation != nil:
-		if handler, ok := b.messageHandlers["Animation"]; ok {
+		if handler, ok := routes.types["Animation"]; ok {
 			ctx.Animation = message.Animation
 			return b.safeExecute(ctx, handler)
 		}
 	case message.Sticker != nil:
-		if handler, ok := b.messageHandlers["Sticker"]; ok {
+		if handler, ok := routes.types["Sticker"]; ok {
 			ctx.Sticker = message.Sticker
 			return b.safeExecute(ctx, handler)
 		}
 	case message.Audio != nil:
-		if handler, ok := b.messageHandlers["Audio"]; ok {
+		if handler, ok := routes.types["Audio"]; ok {
 			ctx.Audio = message.Audio
 			return b.safeExecute(ctx, handler)
 		}
 	case message.VideoNote != nil:
-		if handler, ok := b.messageHandlers["VideoNote"]; ok {
+		if handler, ok := routes.types["VideoNote"]; ok {
 			ctx.VideoNote = message.VideoNote
 			return b.safeExecute(ctx, handler)
 		}
@@ -283,24 +484,22 @@ func (b *Bot) handleMessageUpdate(message *models.Message) error {
 		return &BotError{
 			Code:    http.StatusBadRequest,
 			Message: "Unsupported message type received. No handler is available for this message type.",
-			Err:     fmt.Errorf("no handler found for message type: %v", message),
+			Err:     fmt.Errorf("%w for message type: %v", errNoHandler, message),
 		}
 	}
 
 	return nil
 }
 
-func (b *Bot) safeExecute(ctx *Context, handler Handler) error {
+func (b *Bot) safeExecute(ctx *Context, handler Handler) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			b.logger.Error("Panic in handler execution:", r)
-			err := fmt.Errorf("handler panic: %v", r)
-			if b.errorHandler != nil {
-				b.errorHandler(ctx, err)
-			}
+			err = fmt.Errorf("%w: %v", errHandlerPanic, r)
+			b.reportError(ctx, err)
 		}
 	}()
-	err := handler(ctx)
+	err = handler(ctx)
 	if err != nil {
 		switch {
 		case IsAPIError(err, 403):
@@ -310,25 +509,40 @@ func (b *Bot) safeExecute(ctx *Context, handler Handler) error {
 			b.logger.Info("Rate limited")
 			return err
 		default:
-			if b.errorHandler != nil {
-				b.errorHandler(ctx, err)
-			}
+			b.reportError(ctx, err)
 			return err
 		}
 	}
 	return nil
 }
 
+// reportError passes err to the error handler, unless the update is going to
+// be handled again because of it.
+func (b *Bot) reportError(ctx *Context, err error) {
+	if b.errorHandler == nil || (willRetry(ctx.Context()) && !permanent(err)) {
+		return
+	}
+	b.errorHandler(ctx, err)
+}
+
 // SendMessage
 
-func (b *Bot) SendMessage(chatID int64, text string) error {
-	return makeAPIRequest(b.token, "sendMessage", map[string]interface{}{
+func (b *Bot) SendMessage(chatID int64, text string) (*models.Message, error) {
+	return b.SendMessageCtx(context.Background(), chatID, text)
+}
+
+func (b *Bot) SendMessageCtx(ctx context.Context, chatID int64, text string) (*models.Message, error) {
+	return decodeResult[*models.Message](b.makeAPIRequestWithResult(ctx, "sendMessage", map[string]interface{}{
 		"chat_id": chatID,
 		"text":    text,
-	})
+	}))
 }
 
-func (b *Bot) SendMessageWithOpts(req *SendMessageRequest) error {
+func (b *Bot) SendMessageWithOpts(req *SendMessageRequest) (*models.Message, error) {
+	return b.SendMessageWithOptsCtx(context.Background(), req)
+}
+
+func (b *Bot) SendMessageWithOptsCtx(ctx context.Context, req *SendMessageRequest) (*models.Message, error) {
 	payload := map[string]interface{}{
 		"chat_id": req.ChatId,
 		"text":    req.Text,
@@ -336,7 +550,7 @@ func (b *Bot) SendMessageWithOpts(req *SendMessageRequest) error {
 
 	if req.ParseMode != "" {
 		if req.ParseMode != ParseModeHTML && req.ParseMode != ParseModeMarkdown {
-			return &BotError{
+			return nil, &BotError{
 				Code:    http.StatusBadRequest,
 				Message: "Parse mode can be only 'MarkdownV2' or 'HTML'",
 				Err:     fmt.Errorf("Parse mode can be only 'MarkdownV2' or 'HTML'"),
@@ -349,6 +563,10 @@ func (b *Bot) SendMessageWithOpts(req *SendMessageRequest) error {
 		payload["reply_markup"] = req.ReplyMarkup
 	}
 
+	if req.AllowPaidBroadCast {
+		payload["allow_paid_broadcast"] = req.AllowPaidBroadCast
+	}
+
 	if req.ReplyParams != nil && req.ReplyParams.MessageId != 0 {
 		replyParam := map[string]interface{}{
 			"message_id": req.ReplyParams.MessageId,
@@ -359,20 +577,28 @@ func (b *Bot) SendMessageWithOpts(req *SendMessageRequest) error {
 		payload["reply_parameters"] = replyParam
 	}
 
-	return makeAPIRequest(b.token, "sendMessage", payload)
+	return decodeResult[*models.Message](b.makeAPIRequestWithResult(ctx, "sendMessage", payload))
 }
 
 // Forward Message
 
-func (b *Bot) ForwardMessage(chatId, fromChatId, messageId int64) error {
-	return makeAPIRequest(b.token, "forwardMessage", map[string]interface{}{
+func (b *Bot) ForwardMessage(chatId, fromChatId, messageId int64) (*models.Message, error) {
+	return b.ForwardMessageCtx(context.Background(), chatId, fromChatId, messageId)
+}
+
+func (b *Bot) ForwardMessageCtx(ctx context.Context, chatId, fromChatId, messageId int64) (*models.Message, error) {
+	return decodeResult[*models.Message](b.makeAPIRequestWithResult(ctx, "forwardMessage", map[string]interface{}{
 		"chat_id":      chatId,
 		"from_chat_id": fromChatId,
 		"message_id":   messageId,
-	})
+	}))
+}
+
+func (b *Bot) ForwardMessageWithOpts(req *ForwardMessageRequest) (*models.Message, error) {
+	return b.ForwardMessageWithOptsCtx(context.Background(), req)
 }
 
-func (b *Bot) ForwardMessageWithOpts(req *ForwardMessageRequest) error {
+func (b *Bot) ForwardMessageWithOptsCtx(ctx context.Context, req *ForwardMessageRequest) (*models.Message, error) {
 	payload := map[string]interface{}{
 		"chat_id":      req.ChatId,
 		"from_chat_id": req.FromChatId,
@@ -387,20 +613,28 @@ func (b *Bot) ForwardMessageWithOpts(req *ForwardMessageRequest) error {
 		payload["protect_content"] = req.ProtectContent
 	}
 
-	return makeAPIRequest(b.token, "forwardMessage", payload)
+	return decodeResult[*models.Message](b.makeAPIRequestWithResult(ctx, "forwardMessage", payload))
 }
 
 // ForwardMessages
 
-func (b *Bot) ForwardMessages(chatId, fromChatId int64, messageId []int64) error {
-	return makeAPIRequest(b.token, "forwardMessages", map[string]interface{}{
+func (b *Bot) ForwardMessages(chatId, fromChatId int64, messageId []int64) ([]models.MessageId, error) {
+	return b.ForwardMessagesCtx(context.Background(), chatId, fromChatId, messageId)
+}
+
+func (b *Bot) ForwardMessagesCtx(ctx context.Context, chatId, fromChatId int64, messageId []int64) ([]models.MessageId, error) {
+	return decodeResult[[]models.MessageId](b.makeAPIRequestWithResult(ctx, "forwardMessages", map[string]interface{}{
 		"chat_id":      chatId,
 		"from_chat_id": fromChatId,
 		"message_id":   messageId,
-	})
+	}))
 }
 
-func (b *Bot) ForwardMessagesWithOpts(req *ForwardMessagesRequest) error {
+func (b *Bot) ForwardMessagesWithOpts(req *ForwardMessagesRequest) ([]models.MessageId, error) {
+	return b.ForwardMessagesWithOptsCtx(context.Background(), req)
+}
+
+func (b *Bot) ForwardMessagesWithOptsCtx(ctx context.Context, req *ForwardMessagesRequest) ([]models.MessageId, error) {
 	payload := map[string]interface{}{
 		"chat_id":      req.ChatId,
 		"from_chat_id": req.FromChatId,
@@ -415,20 +649,28 @@ func (b *Bot) ForwardMessagesWithOpts(req *ForwardMessagesRequest) error {
 		payload["protect_content"] = req.ProtectContent
 	}
 
-	return makeAPIRequest(b.token, "forwardMessages", payload)
+	return decodeResult[[]models.MessageId](b.makeAPIRequestWithResult(ctx, "forwardMessages", payload))
 }
 
 // CopyMessage
 
-func (b *Bot) CopyMessage(chatId, fromChatId, messageId int64) error {
-	return makeAPIRequest(b.token, "copyMessage", map[string]interface{}{
+func (b *Bot) CopyMessage(chatId, fromChatId, messageId int64) (*models.MessageId, error) {
+	return b.CopyMessageCtx(context.Background(), chatId, fromChatId, messageId)
+}
+
+func (b *Bot) CopyMessageCtx(ctx context.Context, chatId, fromChatId, messageId int64) (*models.MessageId, error) {
+	return decodeResult[*models.MessageId](b.makeAPIRequestWithResult(ctx, "copyMessage", map[string]interface{}{
 		"chat_id":      chatId,
 		"from_chat_id": fromChatId,
 		"message_id":   messageId,
-	})
+	}))
+}
+
+func (b *Bot) CopyMessageWithOpts(req *CopyMessageRequest) (*models.MessageId, error) {
+	return b.CopyMessageWithOptsCtx(context.Background(), req)
 }
 
-func (b *Bot) CopyMessageWithOpts(req *CopyMessageRequest) error {
+func (b *Bot) CopyMessageWithOptsCtx(ctx context.Context, req *CopyMessageRequest) (*models.MessageId, error) {
 	payload := map[string]interface{}{
 		"chat_id":      req.ChatId,
 		"from_chat_id": req.FromChatId,
@@ -441,7 +683,7 @@ func (b *Bot) CopyMessageWithOpts(req *CopyMessageRequest) error {
 
 	if req.ParseMode != "" {
 		if req.ParseMode != ParseModeHTML && req.ParseMode != ParseModeMarkdown {
-			return &BotError{
+			return nil, &BotError{
 				Code:    http.StatusBadRequest,
 				Message: "Parse mode can be only 'MarkdownV2' or 'HTML'",
 				Err:     fmt.Errorf("Parse mode can be only 'MarkdownV2' or 'HTML'"),
@@ -480,20 +722,28 @@ func (b *Bot) CopyMessageWithOpts(req *CopyMessageRequest) error {
 		payload["reply_parameters"] = replyParam
 	}
 
-	return makeAPIRequest(b.token, "copyMessage", payload)
+	return decodeResult[*models.MessageId](b.makeAPIRequestWithResult(ctx, "copyMessage", payload))
 }
 
 // CopyMessages
 
-func (b *Bot) CopyMessages(chatId, fromChatId int64, messageId []int64) error {
-	return makeAPIRequest(b.token, "copyMessages", map[string]interface{}{
+func (b *Bot) CopyMessages(chatId, fromChatId int64, messageId []int64) ([]models.MessageId, error) {
+	return b.CopyMessagesCtx(context.Background(), chatId, fromChatId, messageId)
+}
+
+func (b *Bot) CopyMessagesCtx(ctx context.Context, chatId, fromChatId int64, messageId []int64) ([]models.MessageId, error) {
+	return decodeResult[[]models.MessageId](b.makeAPIRequestWithResult(ctx, "copyMessages", map[string]interface{}{
 		"chat_id":      chatId,
 		"from_chat_id": fromChatId,
 		"message_id":   messageId,
-	})
+	}))
+}
+
+func (b *Bot) CopyMessagesWithOpts(req *CopyMessagesRequest) ([]models.MessageId, error) {
+	return b.CopyMessagesWithOptsCtx(context.Background(), req)
 }
 
-func (b *Bot) CopyMessagesWithOpts(req *CopyMessagesRequest) error {
+func (b *Bot) CopyMessagesWithOptsCtx(ctx context.Context, req *CopyMessagesRequest) ([]models.MessageId, error) {
 	payload := map[string]interface{}{
 		"chat_id":      req.ChatId,
 		"from_chat_id": req.FromChatId,
@@ -512,101 +762,66 @@ func (b *Bot) CopyMessagesWithOpts(req *CopyMessagesRequest) error {
 		payload["remove_caption"] = req.RemoveCaption
 	}
 
-	return makeAPIRequest(b.token, "copyMessages", payload)
+	return decodeResult[[]models.MessageId](b.makeAPIRequestWithResult(ctx, "copyMessages", payload))
 }
 
-// file_id or url
-func (b *Bot) SendPhoto(req *SendPhotoRequest) error {
-	builder := NewParamBuilder().
-		Add("chat_id", req.ChatId).
-		Add("caption", req.Caption).
-		Add("photo", req.Photo).
-		Add("disable_notification", req.DisableNotification).
-		Add("show_caption_above_media", req.ShowCaptionAboveMedia).
-		Add("has_spoiler", req.HasSpoiler)
-
-	if req.ReplyParams != nil {
-		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
-	}
-	if req.ReplyMarkup != nil {
-		replyBytes, _ := json.Marshal(req.ReplyMarkup)
-		builder.Add("reply_markup", string(replyBytes))
-	}
-
-	params := builder.Build()
-	return makeAPIRequest(b.token, "sendPhoto", params)
+// Send Photo with file_id, URL or upload
+func (b *Bot) SendPhoto(req *SendPhotoRequest) (*models.Message, error) {
+	return b.SendPhotoCtx(context.Background(), req)
 }
 
-// file_path
-func (b *Bot) SendPhotoFile(req *SendPhotoRequest) error {
-	b.logger.Debug("Preparing to send photo")
-
-	if req.Photo == "" {
-		b.logger.Error("Photo is nil in SendPhotoFile request")
-		return &BotError{
+func (b *Bot) SendPhotoCtx(ctx context.Context, req *SendPhotoRequest) (*models.Message, error) {
+	if req.Photo == nil {
+		b.logger.Error("Photo is nil in SendPhoto request")
+		return nil, &BotError{
 			Message: "photo can't be nil",
 		}
 	}
 
 	builder := NewParamBuilder().
 		Add("chat_id", req.ChatId).
-		Add("caption", req.Caption)
+		Add("caption", req.Caption).
+		Add("photo", req.Photo).
+		Add("disable_notification", req.DisableNotification).
+		Add("show_caption_above_media", req.ShowCaptionAboveMedia).
+		Add("has_spoiler", req.HasSpoiler).
+		Add("allow_paid_broadcast", req.AllowPaidBroadCast)
 
 	if req.ReplyParams != nil {
 		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
 	}
-
 	if req.ReplyMarkup != nil {
 		replyBytes, _ := json.Marshal(req.ReplyMarkup)
 		builder.Add("reply_markup", string(replyBytes))
 	}
 
 	params := builder.Build()
-	b.logger.Debug("Sending photo request")
-	return makeMultipartReq(b.token, "sendPhoto", params, "photo", req.Photo)
+	return decodeResult[*models.Message](b.makeRequestWithFiles(ctx, "sendPhoto", params))
 }
 
-// Send Audio with file_id or URL
-func (b *Bot) SendAudio(req *SendAudioRequest) error {
-	builder := NewParamBuilder().
-		Add("chat_id", req.ChatId).
-		Add("audio", req.Audio).
-		Add("caption", req.Caption).
-		Add("duration", req.Duration).
-		Add("performer", req.Performer).
-		Add("title", req.Title).
-		Add("disable_notification", req.DisableNotification).
-		Add("protect_content", req.ProtectContent)
-
-	if req.ReplyParams != nil {
-		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
-	}
-	if req.ReplyMarkup != nil {
-		replyBytes, _ := json.Marshal(req.ReplyMarkup)
-		builder.Add("reply_markup", string(replyBytes))
-	}
-	params := builder.Build()
-	return makeAPIRequest(b.token, "sendAudio", params)
+// Send Audio with file_id, URL or upload
+func (b *Bot) SendAudio(req *SendAudioRequest) (*models.Message, error) {
+	return b.SendAudioCtx(context.Background(), req)
 }
 
-// Send Audio with file path
-func (b *Bot) SendAudioFile(req *SendAudioRequest) error {
-	b.logger.Debug("Preparing to send audio")
-	if req.Audio == "" {
-		b.logger.Error("Audio is nil in SendAudioFile request")
-		return &BotError{
+func (b *Bot) SendAudioCtx(ctx context.Context, req *SendAudioRequest) (*models.Message, error) {
+	if req.Audio == nil {
+		b.logger.Error("Audio is nil in SendAudio request")
+		return nil, &BotError{
 			Message: "audio can't be nil",
 		}
 	}
 
 	builder := NewParamBuilder().
 		Add("chat_id", req.ChatId).
+		Add("audio", req.Audio).
 		Add("caption", req.Caption).
 		Add("duration", req.Duration).
 		Add("performer", req.Performer).
 		Add("title", req.Title).
 		Add("disable_notification", req.DisableNotification).
-		Add("protect_content", req.ProtectContent)
+		Add("protect_content", req.ProtectContent).
+		Add("allow_paid_broadcast", req.AllowPaidBroadCast)
 
 	if req.ReplyParams != nil {
 		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
@@ -616,48 +831,25 @@ func (b *Bot) SendAudioFile(req *SendAudioRequest) error {
 		builder.Add("reply_markup", string(replyBytes))
 	}
 	params := builder.Build()
-	b.logger.Debug("Sending audio request")
-	return makeMultipartReq(b.token, "sendAudio", params, "audio", req.Audio)
+	return decodeResult[*models.Message](b.makeRequestWithFiles(ctx, "sendAudio", params))
 }
 
-// Send Video with file_id or URL
-func (b *Bot) SendVideo(req *SendVideoRequest) error {
-	builder := NewParamBuilder().
-		Add("chat_id", req.ChatId).
-		Add("video", req.Video).
-		Add("caption", req.Caption).
-		Add("duration", req.Duration).
-		Add("width", req.Width).
-		Add("height", req.Height).
-		Add("supports_streaming", req.SupportsStreaming).
-		Add("has_spoiler", req.HasSpoiler).
-		Add("show_caption_above_media", req.ShowCaptionAboveMedia).
-		Add("disable_notification", req.DisableNotification).
-		Add("protect_content", req.ProtectContent)
-
-	if req.ReplyParams != nil {
-		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
-	}
-	if req.ReplyMarkup != nil {
-		replyBytes, _ := json.Marshal(req.ReplyMarkup)
-		builder.Add("reply_markup", string(replyBytes))
-	}
-	params := builder.Build()
-	return makeAPIRequest(b.token, "sendVideo", params)
+// Send Video with file_id, URL or upload
+func (b *Bot) SendVideo(req *SendVideoRequest) (*models.Message, error) {
+	return b.SendVideoCtx(context.Background(), req)
 }
 
-// Send Video with file path
-func (b *Bot) SendVideoFile(req *SendVideoRequest) error {
-	b.logger.Debug("Preparing to send video")
-	if req.Video == "" {
-		b.logger.Error("Video is nil in SendVideoFile request")
-		return &BotError{
+func (b *Bot) SendVideoCtx(ctx context.Context, req *SendVideoRequest) (*models.Message, error) {
+	if req.Video == nil {
+		b.logger.Error("Video is nil in SendVideo request")
+		return nil, &BotError{
 			Message: "video can't be nil",
 		}
 	}
 
 	builder := NewParamBuilder().
 		Add("chat_id", req.ChatId).
+		Add("video", req.Video).
 		Add("caption", req.Caption).
 		Add("duration", req.Duration).
 		Add("width", req.Width).
@@ -666,7 +858,8 @@ func (b *Bot) SendVideoFile(req *SendVideoRequest) error {
 		Add("has_spoiler", req.HasSpoiler).
 		Add("show_caption_above_media", req.ShowCaptionAboveMedia).
 		Add("disable_notification", req.DisableNotification).
-		Add("protect_content", req.ProtectContent)
+		Add("protect_content", req.ProtectContent).
+		Add("allow_paid_broadcast", req.AllowPaidBroadCast)
 
 	if req.ReplyParams != nil {
 		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
@@ -676,47 +869,30 @@ func (b *Bot) SendVideoFile(req *SendVideoRequest) error {
 		builder.Add("reply_markup", string(replyBytes))
 	}
 	params := builder.Build()
-	b.logger.Debug("Sending video request")
-	return makeMultipartReq(b.token, "sendVideo", params, "video", req.Video)
+	return decodeResult[*models.Message](b.makeRequestWithFiles(ctx, "sendVideo", params))
 }
 
-// Send Document with file_id or URL
-func (b *Bot) SendDocument(req *SendDocumentRequest) error {
-	builder := NewParamBuilder().
-		Add("chat_id", req.ChatId).
-		Add("document", req.Document).
-		Add("caption", req.Caption).
-		Add("disable_content_type_detection", req.DisableContentTypeDetection).
-		Add("disable_notification", req.DisableNotification).
-		Add("protect_content", req.ProtectContent)
-
-	if req.ReplyParams != nil {
-		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
-	}
-	if req.ReplyMarkup != nil {
-		replyBytes, _ := json.Marshal(req.ReplyMarkup)
-		builder.Add("reply_markup", string(replyBytes))
-	}
-	params := builder.Build()
-	return makeAPIRequest(b.token, "sendDocument", params)
+// Send Document with file_id, URL or upload
+func (b *Bot) SendDocument(req *SendDocumentRequest) (*models.Message, error) {
+	return b.SendDocumentCtx(context.Background(), req)
 }
 
-// Send Document with file path
-func (b *Bot) SendDocumentFile(req *SendDocumentRequest) error {
-	b.logger.Debug("Preparing to send document")
-	if req.Document == "" {
-		b.logger.Error("Document is nil in SendDocumentFile request")
-		return &BotError{
+func (b *Bot) SendDocumentCtx(ctx context.Context, req *SendDocumentRequest) (*models.Message, error) {
+	if req.Document == nil {
+		b.logger.Error("Document is nil in SendDocument request")
+		return nil, &BotError{
 			Message: "document can't be nil",
 		}
 	}
 
 	builder := NewParamBuilder().
 		Add("chat_id", req.ChatId).
+		Add("document", req.Document).
 		Add("caption", req.Caption).
 		Add("disable_content_type_detection", req.DisableContentTypeDetection).
 		Add("disable_notification", req.DisableNotification).
-		Add("protect_content", req.ProtectContent)
+		Add("protect_content", req.ProtectContent).
+		Add("allow_paid_broadcast", req.AllowPaidBroadCast)
 
 	if req.ReplyParams != nil {
 		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
@@ -726,47 +902,25 @@ func (b *Bot) SendDocumentFile(req *SendDocumentRequest) error {
 		builder.Add("reply_markup", string(replyBytes))
 	}
 	params := builder.Build()
-	b.logger.Debug("Sending document request")
-	return makeMultipartReq(b.token, "sendDocument", params, "document", req.Document)
+	return decodeResult[*models.Message](b.makeRequestWithFiles(ctx, "sendDocument", params))
 }
 
-// Send Animation with file_id or URL
-func (b *Bot) SendAnimation(req *SendAnimationRequest) error {
-	builder := NewParamBuilder().
-		Add("chat_id", req.ChatId).
-		Add("animation", req.Animation).
-		Add("caption", req.Caption).
-		Add("duration", req.Duration).
-		Add("width", req.Width).
-		Add("height", req.Height).
-		Add("has_spoiler", req.HasSpoiler).
-		Add("show_caption_above_media", req.ShowCaptionAboveMedia).
-		Add("disable_notification", req.DisableNotification).
-		Add("protect_content", req.ProtectContent)
-
-	if req.ReplyParams != nil {
-		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
-	}
-	if req.ReplyMarkup != nil {
-		replyBytes, _ := json.Marshal(req.ReplyMarkup)
-		builder.Add("reply_markup", string(replyBytes))
-	}
-	params := builder.Build()
-	return makeAPIRequest(b.token, "sendAnimation", params)
+// Send Animation with file_id, URL or upload
+func (b *Bot) SendAnimation(req *SendAnimationRequest) (*models.Message, error) {
+	return b.SendAnimationCtx(context.Background(), req)
 }
 
-// Send Animation with file path
-func (b *Bot) SendAnimationFile(req *SendAnimationRequest) error {
-	b.logger.Debug("Preparing to send animation")
-	if req.Animation == "" {
-		b.logger.Error("Animation is nil in SendAnimationFile request")
-		return &BotError{
+func (b *Bot) SendAnimationCtx(ctx context.Context, req *SendAnimationRequest) (*models.Message, error) {
+	if req.Animation == nil {
+		b.logger.Error("Animation is nil in SendAnimation request")
+		return nil, &BotError{
 			Message: "animation can't be nil",
 		}
 	}
 
 	builder := NewParamBuilder().
 		Add("chat_id", req.ChatId).
+		Add("animation", req.Animation).
 		Add("caption", req.Caption).
 		Add("duration", req.Duration).
 		Add("width", req.Width).
@@ -774,7 +928,8 @@ func (b *Bot) SendAnimationFile(req *SendAnimationRequest) error {
 		Add("has_spoiler", req.HasSpoiler).
 		Add("show_caption_above_media", req.ShowCaptionAboveMedia).
 		Add("disable_notification", req.DisableNotification).
-		Add("protect_content", req.ProtectContent)
+		Add("protect_content", req.ProtectContent).
+		Add("allow_paid_broadcast", req.AllowPaidBroadCast)
 
 	if req.ReplyParams != nil {
 		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
@@ -784,47 +939,30 @@ func (b *Bot) SendAnimationFile(req *SendAnimationRequest) error {
 		builder.Add("reply_markup", string(replyBytes))
 	}
 	params := builder.Build()
-	b.logger.Debug("Sending animation request")
-	return makeMultipartReq(b.token, "sendAnimation", params, "animation", req.Animation)
+	return decodeResult[*models.Message](b.makeRequestWithFiles(ctx, "sendAnimation", params))
 }
 
-// Send Voice with file_id or URL
-func (b *Bot) SendVoice(req *SendVoiceRequest) error {
-	builder := NewParamBuilder().
-		Add("chat_id", req.ChatId).
-		Add("voice", req.Voice).
-		Add("caption", req.Caption).
-		Add("duration", req.Duration).
-		Add("disable_notification", req.DisableNotification).
-		Add("protect_content", req.ProtectContent)
-
-	if req.ReplyParams != nil {
-		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
-	}
-	if req.ReplyMarkup != nil {
-		replyBytes, _ := json.Marshal(req.ReplyMarkup)
-		builder.Add("reply_markup", string(replyBytes))
-	}
-	params := builder.Build()
-	return makeAPIRequest(b.token, "sendVoice", params)
+// Send Voice with file_id, URL or upload
+func (b *Bot) SendVoice(req *SendVoiceRequest) (*models.Message, error) {
+	return b.SendVoiceCtx(context.Background(), req)
 }
 
-// Send Voice with file path
-func (b *Bot) SendVoiceFile(req *SendVoiceRequest) error {
-	b.logger.Debug("Preparing to send voice")
-	if req.Voice == "" {
-		b.logger.Error("Voice is nil in SendVoiceFile request")
-		return &BotError{
+func (b *Bot) SendVoiceCtx(ctx context.Context, req *SendVoiceRequest) (*models.Message, error) {
+	if req.Voice == nil {
+		b.logger.Error("Voice is nil in SendVoice request")
+		return nil, &BotError{
 			Message: "voice can't be nil",
 		}
 	}
 
 	builder := NewParamBuilder().
 		Add("chat_id", req.ChatId).
+		Add("voice", req.Voice).
 		Add("caption", req.Caption).
 		Add("duration", req.Duration).
 		Add("disable_notification", req.DisableNotification).
-		Add("protect_content", req.ProtectContent)
+		Add("protect_content", req.ProtectContent).
+		Add("allow_paid_broadcast", req.AllowPaidBroadCast)
 
 	if req.ReplyParams != nil {
 		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
@@ -834,47 +972,30 @@ func (b *Bot) SendVoiceFile(req *SendVoiceRequest) error {
 		builder.Add("reply_markup", string(replyBytes))
 	}
 	params := builder.Build()
-	b.logger.Debug("Sending voice request")
-	return makeMultipartReq(b.token, "sendVoice", params, "voice", req.Voice)
+	return decodeResult[*models.Message](b.makeRequestWithFiles(ctx, "sendVoice", params))
 }
 
-// Send VideoNote with file_id or URL
-func (b *Bot) SendVideoNote(req *SendVideoNoteRequest) error {
-	builder := NewParamBuilder().
-		Add("chat_id", req.ChatId).
-		Add("video_note", req.VideoNote).
-		Add("duration", req.Duration).
-		Add("length", req.Length).
-		Add("disable_notification", req.DisableNotification).
-		Add("protect_content", req.ProtectContent)
-
-	if req.ReplyParams != nil {
-		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
-	}
-	if req.ReplyMarkup != nil {
-		replyBytes, _ := json.Marshal(req.ReplyMarkup)
-		builder.Add("reply_markup", string(replyBytes))
-	}
-	params := builder.Build()
-	return makeAPIRequest(b.token, "sendVideoNote", params)
+// Send VideoNote with file_id, URL or upload
+func (b *Bot) SendVideoNote(req *SendVideoNoteRequest) (*models.Message, error) {
+	return b.SendVideoNoteCtx(context.Background(), req)
 }
 
-// Send VideoNote with file path
-func (b *Bot) SendVideoNoteFile(req *SendVideoNoteRequest) error {
-	b.logger.Debug("Preparing to send video note")
-	if req.VideoNote == "" {
-		b.logger.Error("VideoNote is nil in SendVideoNoteFile request")
-		return &BotError{
+func (b *Bot) SendVideoNoteCtx(ctx context.Context, req *SendVideoNoteRequest) (*models.Message, error) {
+	if req.VideoNote == nil {
+		b.logger.Error("VideoNote is nil in SendVideoNote request")
+		return nil, &BotError{
 			Message: "video note can't be nil",
 		}
 	}
 
 	builder := NewParamBuilder().
 		Add("chat_id", req.ChatId).
+		Add("video_note", req.VideoNote).
 		Add("duration", req.Duration).
 		Add("length", req.Length).
 		Add("disable_notification", req.DisableNotification).
-		Add("protect_content", req.ProtectContent)
+		Add("protect_content", req.ProtectContent).
+		Add("allow_paid_broadcast", req.AllowPaidBroadCast)
 
 	if req.ReplyParams != nil {
 		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
@@ -884,43 +1005,28 @@ func (b *Bot) SendVideoNoteFile(req *SendVideoNoteRequest) error {
 		builder.Add("reply_markup", string(replyBytes))
 	}
 	params := builder.Build()
-	b.logger.Debug("Sending video note request")
-	return makeMultipartReq(b.token, "sendVideoNote", params, "video_note", req.VideoNote)
+	return decodeResult[*models.Message](b.makeRequestWithFiles(ctx, "sendVideoNote", params))
 }
 
-// SendSticker sends a sticker using file ID or URL
-func (b *Bot) SendSticker(req *SendStickerRequest) error {
-	builder := NewParamBuilder().
-		Add("chat_id", req.ChatId).
-		Add("sticker", req.Sticker).
-		Add("disable_notification", req.DisableNotification).
-		Add("protect_content", req.ProtectContent)
-
-	if req.ReplyParams != nil {
-		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
-	}
-	if req.ReplyMarkup != nil {
-		replyBytes, _ := json.Marshal(req.ReplyMarkup)
-		builder.Add("reply_markup", string(replyBytes))
-	}
-	params := builder.Build()
-	return makeAPIRequest(b.token, "sendSticker", params)
+// SendSticker sends a sticker using file ID, URL or upload
+func (b *Bot) SendSticker(req *SendStickerRequest) (*models.Message, error) {
+	return b.SendStickerCtx(context.Background(), req)
 }
 
-// SendStickerFile sends a sticker using a local file path
-func (b *Bot) SendStickerFile(req *SendStickerRequest) error {
-	b.logger.Debug("Preparing to send sticker")
-	if req.Sticker == "" {
-		b.logger.Error("Sticker is nil in SendStickerFile request")
-		return &BotError{
+func (b *Bot) SendStickerCtx(ctx context.Context, req *SendStickerRequest) (*models.Message, error) {
+	if req.Sticker == nil {
+		b.logger.Error("Sticker is nil in SendSticker request")
+		return nil, &BotError{
 			Message: "sticker can't be nil",
 		}
 	}
 
 	builder := NewParamBuilder().
 		Add("chat_id", req.ChatId).
+		Add("sticker", req.Sticker).
 		Add("disable_notification", req.DisableNotification).
-		Add("protect_content", req.ProtectContent)
+		Add("protect_content", req.ProtectContent).
+		Add("allow_paid_broadcast", req.AllowPaidBroadCast)
 
 	if req.ReplyParams != nil {
 		builder.Add("reply_to_message_id", req.ReplyParams.MessageId)
@@ -930,23 +1036,68 @@ func (b *Bot) SendStickerFile(req *SendStickerRequest) error {
 		builder.Add("reply_markup", string(replyBytes))
 	}
 	params := builder.Build()
-	b.logger.Debug("Sending sticker request")
-	return makeMultipartReq(b.token, "sendSticker", params, "sticker", req.Sticker)
+	return decodeResult[*models.Message](b.makeRequestWithFiles(ctx, "sendSticker", params))
 }
 
 // SendMediaGroup
-func (b *Bot) SendMediaGroup(chatID int64, media []InputMedia, files []MediaFile) error {
-	req := &SendMediaGroupRequest{
+func (b *Bot) SendMediaGroup(chatID int64, media []InputMedia) ([]models.Message, error) {
+	return b.SendMediaGroupCtx(context.Background(), chatID, media)
+}
+
+func (b *Bot) SendMediaGroupCtx(ctx context.Context, chatID int64, media []InputMedia) ([]models.Message, error) {
+	return b.SendMediaGroupWithOptsCtx(ctx, &SendMediaGroupRequest{
 		ChatID: chatID,
 		Media:  media,
+	})
+}
+
+func (b *Bot) SendMediaGroupWithOpts(req *SendMediaGroupRequest) ([]models.Message, error) {
+	return b.SendMediaGroupWithOptsCtx(context.Background(), req)
+}
+
+func (b *Bot) SendMediaGroupWithOptsCtx(ctx context.Context, req *SendMediaGroupRequest) ([]models.Message, error) {
+	// Uploads can't be embedded in the media JSON, so each one is sent as its
+	// own form field and referenced from the array as attach://<field>.
+	media := make([]InputMedia, len(req.Media))
+	files := make(map[string]*InputFile)
+	for i, item := range req.Media {
+		if item.Media == nil {
+			return nil, &BotError{
+				Code:    http.StatusBadRequest,
+				Message: "media can't be nil",
+				Err:     fmt.Errorf("media group item %d has no media", i),
+			}
+		}
+		if item.Media.isUpload() {
+			attached := *item.Media
+			attached.attach = fmt.Sprintf("file%d", i)
+			files[attached.attach] = &attached
+			item.Media = &attached
+		}
+		media[i] = item
+	}
+
+	params := NewParamBuilder().
+		Add("chat_id", req.ChatID).
+		Add("disable_notification", req.DisableNotification).
+		Add("protect_content", req.ProtectContent).
+		Add("allow_paid_broadcast", req.AllowPaidBroadCast).
+		Build()
+	params["media"] = media
+	if req.ReplyParams != nil {
+		params["reply_parameters"] = req.ReplyParams
 	}
 
-	return makeMultipartMediaGroupReq(b.token, "sendMediaGroup", req, files)
+	return decodeResult[[]models.Message](b.makeUploadRequest(ctx, "sendMediaGroup", params, files))
 }
 
 // sendChatAction
 func (b *Bot) SendChatAction(chatId int64, action string) error {
-	return makeAPIRequest(b.token, "sendChatAction", map[string]interface{}{
+	return b.SendChatActionCtx(context.Background(), chatId, action)
+}
+
+func (b *Bot) SendChatActionCtx(ctx context.Context, chatId int64, action string) error {
+	return b.makeAPIRequest(ctx, "sendChatAction", map[string]interface{}{
 		"chat_id": chatId,
 		"action":  action,
 	})
@@ -958,6 +1109,10 @@ func boolPtr(b bool) *bool {
 
 // banChatMember
 func (b *Bot) BanChatMember(chatId string, userId, untilDate int32, revokeMessages *bool) error {
+	return b.BanChatMemberCtx(context.Background(), chatId, userId, untilDate, revokeMessages)
+}
+
+func (b *Bot) BanChatMemberCtx(ctx context.Context, chatId string, userId, untilDate int32, revokeMessages *bool) error {
 	params := map[string]interface{}{
 		"chat_id":    chatId,
 		"user_id":    userId,
@@ -968,11 +1123,15 @@ func (b *Bot) BanChatMember(chatId string, userId, untilDate int32, revokeMessag
 		params["revoke_messages"] = *revokeMessages
 	}
 
-	return makeAPIRequest(b.token, "banChatMember", params)
+	return b.makeAPIRequest(ctx, "banChatMember", params)
 }
 
 // unbanChatMember
 func (b *Bot) UnbanChatMember(chatId string, userId int32, onlyIfBanned *bool) error {
+	return b.UnbanChatMemberCtx(context.Background(), chatId, userId, onlyIfBanned)
+}
+
+func (b *Bot) UnbanChatMemberCtx(ctx context.Context, chatId string, userId int32, onlyIfBanned *bool) error {
 	params := map[string]interface{}{
 		"chat_id": chatId,
 		"user_id": userId,
@@ -982,11 +1141,15 @@ func (b *Bot) UnbanChatMember(chatId string, userId int32, onlyIfBanned *bool) e
 		params["only_if_banned"] = *onlyIfBanned
 	}
 
-	return makeAPIRequest(b.token, "unbanChatMember", params)
+	return b.makeAPIRequest(ctx, "unbanChatMember", params)
 }
 
 // restrictChatMember
 func (b *Bot) RestrictChatMember(req *RestrictChatMember) error {
+	return b.RestrictChatMemberCtx(context.Background(), req)
+}
+
+func (b *Bot) RestrictChatMemberCtx(ctx context.Context, req *RestrictChatMember) error {
 	if req.ChatId == "" {
 		return fmt.Errorf("chat_id is required")
 	}
@@ -1005,11 +1168,15 @@ func (b *Bot) RestrictChatMember(req *RestrictChatMember) error {
 		"until_date":                       req.UntilDate,
 	}
 
-	return makeAPIRequest(b.token, "restrictChatMember", params)
+	return b.makeAPIRequest(ctx, "restrictChatMember", params)
 }
 
 // PromoteChatMember promotes a user to an administrator in a chat
 func (b *Bot) PromoteChatMember(req *PromoteChatMember) error {
+	return b.PromoteChatMemberCtx(context.Background(), req)
+}
+
+func (b *Bot) PromoteChatMemberCtx(ctx context.Context, req *PromoteChatMember) error {
 	if req.ChatId == "" {
 		return fmt.Errorf("chat_id is required")
 	}
@@ -1036,7 +1203,7 @@ func (b *Bot) PromoteChatMember(req *PromoteChatMember) error {
 		Add("can_pin_messages", req.CanPinMessages).
 		Add("can_manage_topics", req.CanManageTopics)
 
-	err := makeAPIRequest(b.token, "promoteChatMember", builder.Build())
+	err := b.makeAPIRequest(ctx, "promoteChatMember", builder.Build())
 	if err != nil {
 		return fmt.Errorf("failed to promote chat member: %w", err)
 	}
@@ -1046,7 +1213,11 @@ func (b *Bot) PromoteChatMember(req *PromoteChatMember) error {
 
 // setChatAdministratorCustomTitle
 func (b *Bot) SetChatAdministratorCustomTitle(chatId string, userId int32, customTitle string) error {
-	return makeAPIRequest(b.token, "setChatAdministratorCustomTitle", map[string]interface{}{
+	return b.SetChatAdministratorCustomTitleCtx(context.Background(), chatId, userId, customTitle)
+}
+
+func (b *Bot) SetChatAdministratorCustomTitleCtx(ctx context.Context, chatId string, userId int32, customTitle string) error {
+	return b.makeAPIRequest(ctx, "setChatAdministratorCustomTitle", map[string]interface{}{
 		"chat_id":      chatId,
 		"user_id":      userId,
 		"custom_title": customTitle,
@@ -1055,7 +1226,11 @@ func (b *Bot) SetChatAdministratorCustomTitle(chatId string, userId int32, custo
 
 // banChatSenderChat
 func (b *Bot) BanChatSenderChat(chatId string, senderChatId int32) error {
-	return makeAPIRequest(b.token, "banChatSenderChat", map[string]interface{}{
+	return b.BanChatSenderChatCtx(context.Background(), chatId, senderChatId)
+}
+
+func (b *Bot) BanChatSenderChatCtx(ctx context.Context, chatId string, senderChatId int32) error {
+	return b.makeAPIRequest(ctx, "banChatSenderChat", map[string]interface{}{
 		"chat_id":        chatId,
 		"sender_chat_id": senderChatId,
 	})
@@ -1063,7 +1238,11 @@ func (b *Bot) BanChatSenderChat(chatId string, senderChatId int32) error {
 
 // unbanChatSenderChat
 func (b *Bot) UnbanChatSenderChat(chatId string, senderChatId int32) error {
-	return makeAPIRequest(b.token, "unbanChatSenderChat", map[string]interface{}{
+	return b.UnbanChatSenderChatCtx(context.Background(), chatId, senderChatId)
+}
+
+func (b *Bot) UnbanChatSenderChatCtx(ctx context.Context, chatId string, senderChatId int32) error {
+	return b.makeAPIRequest(ctx, "unbanChatSenderChat", map[string]interface{}{
 		"chat_id":        chatId,
 		"sender_chat_id": senderChatId,
 	})
@@ -1071,6 +1250,10 @@ func (b *Bot) UnbanChatSenderChat(chatId string, senderChatId int32) error {
 
 // setChatPermissions
 func (b *Bot) SetChatPermissions(chatId string, chatPermissions ChatPermissions, useIndependentChatPermissions *bool) error {
+	return b.SetChatPermissionsCtx(context.Background(), chatId, chatPermissions, useIndependentChatPermissions)
+}
+
+func (b *Bot) SetChatPermissionsCtx(ctx context.Context, chatId string, chatPermissions ChatPermissions, useIndependentChatPermissions *bool) error {
 	permissions, _ := json.Marshal(chatPermissions)
 	params := map[string]interface{}{
 		"chat_id":                          chatId,
@@ -1081,13 +1264,17 @@ func (b *Bot) SetChatPermissions(chatId string, chatPermissions ChatPermissions,
 		params["use_independent_chat_permissions"] = *useIndependentChatPermissions
 	}
 
-	return makeAPIRequest(b.token, "setChatPermissions", params)
+	return b.makeAPIRequest(ctx, "setChatPermissions", params)
 }
 
 // exportChatInviteLink
 func (b *Bot) ExportChatInviteLink(chatId string) (string, error) {
+	return b.ExportChatInviteLinkCtx(context.Background(), chatId)
+}
+
+func (b *Bot) ExportChatInviteLinkCtx(ctx context.Context, chatId string) (string, error) {
 	// Make the API request, assuming it returns json.RawMessage
-	response, err := makeAPIRequestWithResult(b.token, "exportChatInviteLink", map[string]interface{}{
+	response, err := b.makeAPIRequestWithResult(ctx, "exportChatInviteLink", map[string]interface{}{
 		"chat_id": chatId,
 	})
 	if err != nil {
@@ -1109,7 +1296,11 @@ func (b *Bot) ExportChatInviteLink(chatId string) (string, error) {
 
 // createChatInviteLink
 func (b *Bot) CreateChatInviteLink(chatId string) (map[string]interface{}, error) {
-	response, err := makeAPIRequestWithResult(b.token, "createChatInviteLink", map[string]interface{}{
+	return b.CreateChatInviteLinkCtx(context.Background(), chatId)
+}
+
+func (b *Bot) CreateChatInviteLinkCtx(ctx context.Context, chatId string) (map[string]interface{}, error) {
+	response, err := b.makeAPIRequestWithResult(ctx, "createChatInviteLink", map[string]interface{}{
 		"chat_id": chatId,
 	})
 	if err != nil {
@@ -1131,6 +1322,10 @@ func (b *Bot) CreateChatInviteLink(chatId string) (map[string]interface{}, error
 
 // editChatInviteLink
 func (b *Bot) EditChatInviteLink(chatId string, inviteLink string, name *string, expireDate *int32, memberLimit *int32, createsJoinRequest *bool) (map[string]interface{}, error) {
+	return b.EditChatInviteLinkCtx(context.Background(), chatId, inviteLink, name, expireDate, memberLimit, createsJoinRequest)
+}
+
+func (b *Bot) EditChatInviteLinkCtx(ctx context.Context, chatId string, inviteLink string, name *string, expireDate *int32, memberLimit *int32, createsJoinRequest *bool) (map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"chat_id":     chatId,
 		"invite_link": inviteLink,
@@ -1149,7 +1344,7 @@ func (b *Bot) EditChatInviteLink(chatId string, inviteLink string, name *string,
 		params["creates_join_request"] = *createsJoinRequest
 	}
 
-	response, err := makeAPIRequestWithResult(b.token, "editChatInviteLink", params)
+	response, err := b.makeAPIRequestWithResult(ctx, "editChatInviteLink", params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to edit chat invite link: %w", err)
 	}
@@ -1168,6 +1363,10 @@ func (b *Bot) EditChatInviteLink(chatId string, inviteLink string, name *string,
 }
 
 func (b *Bot) CreateChatSubscriptionInviteLink(chatId string, name *string, subscriptionPeriod int32, subscriptionPrice int32) (map[string]interface{}, error) {
+	return b.CreateChatSubscriptionInviteLinkCtx(context.Background(), chatId, name, subscriptionPeriod, subscriptionPrice)
+}
+
+func (b *Bot) CreateChatSubscriptionInviteLinkCtx(ctx context.Context, chatId string, name *string, subscriptionPeriod int32, subscriptionPrice int32) (map[string]interface{}, error) {
 	if subscriptionPeriod != 2592000 {
 		return nil, fmt.Errorf("subscription period must always be 2592000 (30 days)")
 	}
@@ -1185,7 +1384,7 @@ func (b *Bot) CreateChatSubscriptionInviteLink(chatId string, name *string, subs
 		params["name"] = *name
 	}
 
-	response, err := makeAPIRequestWithResult(b.token, "createChatSubscriptionInviteLink", params)
+	response, err := b.makeAPIRequestWithResult(ctx, "createChatSubscriptionInviteLink", params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create chat subscription invite link: %w", err)
 	}
@@ -1204,6 +1403,10 @@ func (b *Bot) CreateChatSubscriptionInviteLink(chatId string, name *string, subs
 }
 
 func (b *Bot) EditChatSubscriptionInviteLink(chatId string, inviteLink string, name *string) (map[string]interface{}, error) {
+	return b.EditChatSubscriptionInviteLinkCtx(context.Background(), chatId, inviteLink, name)
+}
+
+func (b *Bot) EditChatSubscriptionInviteLinkCtx(ctx context.Context, chatId string, inviteLink string, name *string) (map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"chat_id":     chatId,
 		"invite_link": inviteLink,
@@ -1213,7 +1416,7 @@ func (b *Bot) EditChatSubscriptionInviteLink(chatId string, inviteLink string, n
 		params["name"] = *name
 	}
 
-	response, err := makeAPIRequestWithResult(b.token, "editChatSubscriptionInviteLink", params)
+	response, err := b.makeAPIRequestWithResult(ctx, "editChatSubscriptionInviteLink", params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to edit chat subscription invite link: %w", err)
 	}
@@ -1232,12 +1435,16 @@ func (b *Bot) EditChatSubscriptionInviteLink(chatId string, inviteLink string, n
 }
 
 func (b *Bot) RevokeChatInviteLink(chatId string, inviteLink string) (map[string]interface{}, error) {
+	return b.RevokeChatInviteLinkCtx(context.Background(), chatId, inviteLink)
+}
+
+func (b *Bot) RevokeChatInviteLinkCtx(ctx context.Context, chatId string, inviteLink string) (map[string]interface{}, error) {
 	params := map[string]interface{}{
 		"chat_id":     chatId,
 		"invite_link": inviteLink,
 	}
 
-	response, err := makeAPIRequestWithResult(b.token, "revokeChatInviteLink", params)
+	response, err := b.makeAPIRequestWithResult(ctx, "revokeChatInviteLink", params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to revoke chat invite link: %w", err)
 	}
@@ -1256,12 +1463,16 @@ func (b *Bot) RevokeChatInviteLink(chatId string, inviteLink string) (map[string
 }
 
 func (b *Bot) ApproveChatJoinRequest(chatId string, userId int) (bool, error) {
+	return b.ApproveChatJoinRequestCtx(context.Background(), chatId, userId)
+}
+
+func (b *Bot) ApproveChatJoinRequestCtx(ctx context.Context, chatId string, userId int) (bool, error) {
 	params := map[string]interface{}{
 		"chat_id": chatId,
 		"user_id": userId,
 	}
 
-	response, err := makeAPIRequestWithResult(b.token, "approveChatJoinRequest", params)
+	response, err := b.makeAPIRequestWithResult(ctx, "approveChatJoinRequest", params)
 	if err != nil {
 		return false, fmt.Errorf("failed to approve chat join request: %w", err)
 	}
@@ -1280,12 +1491,16 @@ func (b *Bot) ApproveChatJoinRequest(chatId string, userId int) (bool, error) {
 }
 
 func (b *Bot) DeclineChatJoinRequest(chatId string, userId int) (bool, error) {
+	return b.DeclineChatJoinRequestCtx(context.Background(), chatId, userId)
+}
+
+func (b *Bot) DeclineChatJoinRequestCtx(ctx context.Context, chatId string, userId int) (bool, error) {
 	params := map[string]interface{}{
 		"chat_id": chatId,
 		"user_id": userId,
 	}
 
-	response, err := makeAPIRequestWithResult(b.token, "declineChatJoinRequest", params)
+	response, err := b.makeAPIRequestWithResult(ctx, "declineChatJoinRequest", params)
 	if err != nil {
 		return false, fmt.Errorf("failed to decline chat join request: %w", err)
 	}
@@ -1303,12 +1518,17 @@ func (b *Bot) DeclineChatJoinRequest(chatId string, userId int) (bool, error) {
 	return declined, nil
 }
 
-func (b *Bot) SetChatPhoto(chatId string, photoPath string) (bool, error) {
+func (b *Bot) SetChatPhoto(chatId string, photo *InputFile) (bool, error) {
+	return b.SetChatPhotoCtx(context.Background(), chatId, photo)
+}
+
+func (b *Bot) SetChatPhotoCtx(ctx context.Context, chatId string, photo *InputFile) (bool, error) {
 	params := map[string]interface{}{
 		"chat_id": chatId,
+		"photo":   photo,
 	}
 
-	err := makeMultipartReq(b.token, "setChatPhoto", params, "photo", photoPath)
+	_, err := b.makeRequestWithFiles(ctx, "setChatPhoto", params)
 	if err != nil {
 		return false, fmt.Errorf("failed to set chat photo: %w", err)
 	}
@@ -1317,27 +1537,43 @@ func (b *Bot) SetChatPhoto(chatId string, photoPath string) (bool, error) {
 }
 
 func (b *Bot) DeleteChatPhoto(chatId string) error {
-	return makeAPIRequest(b.token, "deleteChatPhoto", map[string]interface{}{
+	return b.DeleteChatPhotoCtx(context.Background(), chatId)
+}
+
+func (b *Bot) DeleteChatPhotoCtx(ctx context.Context, chatId string) error {
+	return b.makeAPIRequest(ctx, "deleteChatPhoto", map[string]interface{}{
 		"chat_id": chatId,
 	})
 }
 
 func (b *Bot) SetChatTitle(chatId, title string) error {
-	return makeAPIRequest(b.token, "setChatTitle", map[string]interface{}{
+	return b.SetChatTitleCtx(context.Background(), chatId, title)
+}
+
+func (b *Bot) SetChatTitleCtx(ctx context.Context, chatId, title string) error {
+	return b.makeAPIRequest(ctx, "setChatTitle", map[string]interface{}{
 		"chat_id": chatId,
 		"title":   title,
 	})
 }
 
 func (b *Bot) SetChatDescription(chatId, description string) error {
-	return makeAPIRequest(b.token, "setChatDescription", map[string]interface{}{
+	return b.SetChatDescriptionCtx(context.Background(), chatId, description)
+}
+
+func (b *Bot) SetChatDescriptionCtx(ctx context.Context, chatId, description string) error {
+	return b.makeAPIRequest(ctx, "setChatDescription", map[string]interface{}{
 		"chat_id":     chatId,
 		"description": description,
 	})
 }
 
 func (b *Bot) PinChatMessage(chatId string, messageId int64, DisableNotification bool) error {
-	return makeAPIRequest(b.token, "pinChatMessage", map[string]interface{}{
+	return b.PinChatMessageCtx(context.Background(), chatId, messageId, DisableNotification)
+}
+
+func (b *Bot) PinChatMessageCtx(ctx context.Context, chatId string, messageId int64, DisableNotification bool) error {
+	return b.makeAPIRequest(ctx, "pinChatMessage", map[string]interface{}{
 		"chat_id":              chatId,
 		"message_id":           messageId,
 		"disable_notification": DisableNotification,
@@ -1345,67 +1581,107 @@ func (b *Bot) PinChatMessage(chatId string, messageId int64, DisableNotification
 }
 
 func (b *Bot) UnpinChatMessage(chatId string, messageId int64) error {
-	return makeAPIRequest(b.token, "unpinChatMessage", map[string]interface{}{
+	return b.UnpinChatMessageCtx(context.Background(), chatId, messageId)
+}
+
+func (b *Bot) UnpinChatMessageCtx(ctx context.Context, chatId string, messageId int64) error {
+	return b.makeAPIRequest(ctx, "unpinChatMessage", map[string]interface{}{
 		"chat_id":    chatId,
 		"message_id": messageId,
 	})
 }
 
 func (b *Bot) UnpinAllChatMessages(chatId string) error {
-	return makeAPIRequest(b.token, "unpinAllChatMessages", map[string]interface{}{
+	return b.UnpinAllChatMessagesCtx(context.Background(), chatId)
+}
+
+func (b *Bot) UnpinAllChatMessagesCtx(ctx context.Context, chatId string) error {
+	return b.makeAPIRequest(ctx, "unpinAllChatMessages", map[string]interface{}{
 		"chat_id": chatId,
 	})
 }
 
 func (b *Bot) LeaveChat(chatId string) error {
-	return makeAPIRequest(b.token, "leaveChat", map[string]interface{}{
+	return b.LeaveChatCtx(context.Background(), chatId)
+}
+
+func (b *Bot) LeaveChatCtx(ctx context.Context, chatId string) error {
+	return b.makeAPIRequest(ctx, "leaveChat", map[string]interface{}{
 		"chat_id": chatId,
 	})
 }
 
 func (b *Bot) GetChat(chatId string) (json.RawMessage, error) {
-	return makeAPIRequestWithResult(b.token, "getChat", map[string]interface{}{
+	return b.GetChatCtx(context.Background(), chatId)
+}
+
+func (b *Bot) GetChatCtx(ctx context.Context, chatId string) (json.RawMessage, error) {
+	return b.makeAPIRequestWithResult(ctx, "getChat", map[string]interface{}{
 		"chat_id": chatId,
 	})
 }
 
 func (b *Bot) GetChatAdministrators(chatId string) (json.RawMessage, error) {
-	return makeAPIRequestWithResult(b.token, "getChatAdministrators", map[string]interface{}{
+	return b.GetChatAdministratorsCtx(context.Background(), chatId)
+}
+
+func (b *Bot) GetChatAdministratorsCtx(ctx context.Context, chatId string) (json.RawMessage, error) {
+	return b.makeAPIRequestWithResult(ctx, "getChatAdministrators", map[string]interface{}{
 		"chat_id": chatId,
 	})
 }
 
 func (b *Bot) GetChatMemberCount(chatId string) (json.RawMessage, error) {
-	return makeAPIRequestWithResult(b.token, "getChatMemberCount", map[string]interface{}{
+	return b.GetChatMemberCountCtx(context.Background(), chatId)
+}
+
+func (b *Bot) GetChatMemberCountCtx(ctx context.Context, chatId string) (json.RawMessage, error) {
+	return b.makeAPIRequestWithResult(ctx, "getChatMemberCount", map[string]interface{}{
 		"chat_id": chatId,
 	})
 }
 
 func (b *Bot) GetChatMember(chatId string, userId int64) (json.RawMessage, error) {
-	return makeAPIRequestWithResult(b.token, "getChatMember", map[string]interface{}{
+	return b.GetChatMemberCtx(context.Background(), chatId, userId)
+}
+
+func (b *Bot) GetChatMemberCtx(ctx context.Context, chatId string, userId int64) (json.RawMessage, error) {
+	return b.makeAPIRequestWithResult(ctx, "getChatMember", map[string]interface{}{
 		"chat_id": chatId,
 		"user_id": userId,
 	})
 }
 
 func (b *Bot) SetStickerSet(chatId, stickerSetName string) (json.RawMessage, error) {
-	return makeAPIRequestWithResult(b.token, "setStickerSet", map[string]interface{}{
+	return b.SetStickerSetCtx(context.Background(), chatId, stickerSetName)
+}
+
+func (b *Bot) SetStickerSetCtx(ctx context.Context, chatId, stickerSetName string) (json.RawMessage, error) {
+	return b.makeAPIRequestWithResult(ctx, "setStickerSet", map[string]interface{}{
 		"chat_id":          chatId,
 		"sticker_set_name": stickerSetName,
 	})
 }
 
 func (b *Bot) DeleteStickerSet(chatId string) (json.RawMessage, error) {
-	return makeAPIRequestWithResult(b.token, "deleteChatStickerSet", map[string]interface{}{
+	return b.DeleteStickerSetCtx(context.Background(), chatId)
+}
+
+func (b *Bot) DeleteStickerSetCtx(ctx context.Context, chatId string) (json.RawMessage, error) {
+	return b.makeAPIRequestWithResult(ctx, "deleteChatStickerSet", map[string]interface{}{
 		"chat_id": chatId,
 	})
 }
 
 func (b *Bot) GetForumTopicIconStickers() (json.RawMessage, error) {
-	return makeAPIRequestWithResult(b.token, "getForumTopicIconStickers", map[string]interface{}{})
+	return b.GetForumTopicIconStickersCtx(context.Background())
+}
+
+func (b *Bot) GetForumTopicIconStickersCtx(ctx context.Context) (json.RawMessage, error) {
+	return b.makeAPIRequestWithResult(ctx, "getForumTopicIconStickers", map[string]interface{}{})
 }
 
-func (b *Bot) answerCallbackQuery(req *AnswerCallbackQueryRequest) error {
+func (b *Bot) answerCallbackQuery(ctx context.Context, req *AnswerCallbackQueryRequest) error {
 	params := map[string]interface{}{
 		"callback_query_id": req.CallbackQueryId,
 	}
@@ -1426,71 +1702,115 @@ func (b *Bot) answerCallbackQuery(req *AnswerCallbackQueryRequest) error {
 		params["cache_time"] = req.CacheTime
 	}
 
-	return makeAPIRequest(b.token, "answerCallbackQuery", params)
+	return b.makeAPIRequest(ctx, "answerCallbackQuery", params)
 }
 
 func (b *Bot) GetUserChatBoosts(chatId string, userId int64) (json.RawMessage, error) {
-	return makeAPIRequestWithResult(b.token, "getUserChatBoosts", map[string]interface{}{
+	return b.GetUserChatBoostsCtx(context.Background(), chatId, userId)
+}
+
+func (b *Bot) GetUserChatBoostsCtx(ctx context.Context, chatId string, userId int64) (json.RawMessage, error) {
+	return b.makeAPIRequestWithResult(ctx, "getUserChatBoosts", map[string]interface{}{
 		"chat_id": chatId,
 		"user_id": userId,
 	})
 }
 
 func (b *Bot) SetMyCommands(commands []BotCommand) error {
+	return b.SetMyCommandsCtx(context.Background(), commands)
+}
+
+func (b *Bot) SetMyCommandsCtx(ctx context.Context, commands []BotCommand) error {
 	botCommands, _ := json.Marshal(commands)
-	return makeAPIRequest(b.token, "setMyCommands", map[string]interface{}{
+	return b.makeAPIRequest(ctx, "setMyCommands", map[string]interface{}{
 		"commands": string(botCommands),
 	})
 }
 
 func (b *Bot) DeleteMyCommands() error {
-	return makeAPIRequest(b.token, "deleteMyCommands", map[string]interface{}{})
+	return b.DeleteMyCommandsCtx(context.Background())
+}
+
+func (b *Bot) DeleteMyCommandsCtx(ctx context.Context) error {
+	return b.makeAPIRequest(ctx, "deleteMyCommands", map[string]interface{}{})
 }
 
 func (b *Bot) GetMyCommands() (json.RawMessage, error) {
-	return makeAPIRequestWithResult(b.token, "getMyCommands", map[string]interface{}{})
+	return b.GetMyCommandsCtx(context.Background())
+}
+
+func (b *Bot) GetMyCommandsCtx(ctx context.Context) (json.RawMessage, error) {
+	return b.makeAPIRequestWithResult(ctx, "getMyCommands", map[string]interface{}{})
 }
 
 func (b *Bot) SetMyName(name, langagueCode string) (json.RawMessage, error) {
-	return makeAPIRequestWithResult(b.token, "setMyName", map[string]interface{}{
+	return b.SetMyNameCtx(context.Background(), name, langagueCode)
+}
+
+func (b *Bot) SetMyNameCtx(ctx context.Context, name, langagueCode string) (json.RawMessage, error) {
+	return b.makeAPIRequestWithResult(ctx, "setMyName", map[string]interface{}{
 		"name":          name,
 		"language_code": langagueCode,
 	})
 }
 
 func (b *Bot) GetMyName(name, langagueCode string) (json.RawMessage, error) {
-	return makeAPIRequestWithResult(b.token, "getMyName", map[string]interface{}{
+	return b.GetMyNameCtx(context.Background(), name, langagueCode)
+}
+
+func (b *Bot) GetMyNameCtx(ctx context.Context, name, langagueCode string) (json.RawMessage, error) {
+	return b.makeAPIRequestWithResult(ctx, "getMyName", map[string]interface{}{
 		"language_code": langagueCode,
 	})
 }
 
 func (b *Bot) SetMyDescription(description, langagueCode string) error {
-	return makeAPIRequest(b.token, "setMyDescription", map[string]interface{}{
+	return b.SetMyDescriptionCtx(context.Background(), description, langagueCode)
+}
+
+func (b *Bot) SetMyDescriptionCtx(ctx context.Context, description, langagueCode string) error {
+	return b.makeAPIRequest(ctx, "setMyDescription", map[string]interface{}{
 		"description":   description,
 		"language_code": langagueCode,
 	})
 }
 
 func (b *Bot) GetMyDescription(description, langagueCode string) (json.RawMessage, error) {
-	return makeAPIRequestWithResult(b.token, "getMyDescription", map[string]interface{}{
+	return b.GetMyDescriptionCtx(context.Background(), description, langagueCode)
+}
+
+func (b *Bot) GetMyDescriptionCtx(ctx context.Context, description, langagueCode string) (json.RawMessage, error) {
+	return b.makeAPIRequestWithResult(ctx, "getMyDescription", map[string]interface{}{
 		"language_code": langagueCode,
 	})
 }
 
 func (b *Bot) SetMyShortDescription(shortDescription, langagueCode string) error {
-	return makeAPIRequest(b.token, "setMyShortDescription", map[string]interface{}{
+	return b.SetMyShortDescriptionCtx(context.Background(), shortDescription, langagueCode)
+}
+
+func (b *Bot) SetMyShortDescriptionCtx(ctx context.Context, shortDescription, langagueCode string) error {
+	return b.makeAPIRequest(ctx, "setMyShortDescription", map[string]interface{}{
 		"short_description": shortDescription,
 		"language_code":     langagueCode,
 	})
 }
 
 func (b *Bot) GetMyShortDescription(langagueCode string) (json.RawMessage, error) {
-	return makeAPIRequestWithResult(b.token, "getMyShortDescription", map[string]interface{}{
+	return b.GetMyShortDescriptionCtx(context.Background(), langagueCode)
+}
+
+func (b *Bot) GetMyShortDescriptionCtx(ctx context.Context, langagueCode string) (json.RawMessage, error) {
+	return b.makeAPIRequestWithResult(ctx, "getMyShortDescription", map[string]interface{}{
 		"language_code": langagueCode,
 	})
 }
 
 func (b *Bot) EditMessageText(req *EditMessageTextRequest) error {
+	return b.EditMessageTextCtx(context.Background(), req)
+}
+
+func (b *Bot) EditMessageTextCtx(ctx context.Context, req *EditMessageTextRequest) error {
 	builer := NewParamBuilder().Add("chat_id", req.ChatId).Add("message_id", req.MessageId)
-	return makeAPIRequest(b.token, "getMyShortDescription", builer.Build())
+	return b.makeAPIRequest(ctx, "getMyShortDescription", builer.Build())
 }
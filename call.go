@@ -0,0 +1,115 @@
+package tgx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Call invokes any Bot API method, including ones this package does not wrap
+// yet, and decodes its result into T:
+//
+//	chat, err := tgx.Call[map[string]any](bot, "getChat", map[string]any{"chat_id": id})
+//
+// params may be nil, a map[string]interface{} or a struct (or pointer to one)
+// whose fields are named by their json tags. Any *InputFile or io.Reader value
+// that needs uploading switches the request to multipart/form-data. The call goes
+// through the same rate limiting, retries and logging as the built-in methods.
+func Call[T any](b *Bot, method string, params any) (T, error) {
+	return CallCtx[T](context.Background(), b, method, params)
+}
+
+func CallCtx[T any](ctx context.Context, b *Bot, method string, params any) (T, error) {
+	payload, err := toParams(params)
+	if err != nil {
+		var zero T
+		return zero, &BotError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request parameters",
+			Err:     err,
+		}
+	}
+
+	return decodeResult[T](b.makeRequestWithFiles(ctx, method, payload))
+}
+
+// toParams turns the params of Call into a parameter map.
+func toParams(params any) (map[string]interface{}, error) {
+	switch p := params.(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case map[string]interface{}:
+		return p, nil
+	case map[string]string:
+		out := make(map[string]interface{}, len(p))
+		for k, v := range p {
+			out[k] = v
+		}
+		return out, nil
+	}
+
+	v := reflect.ValueOf(params)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return map[string]interface{}{}, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("params must be a struct or a map, got %T", params)
+	}
+
+	out := make(map[string]interface{})
+	structParams(v, out)
+	return out, nil
+}
+
+// structParams copies the fields of a struct into out following encoding/json
+// naming rules, but keeps the values as they are so files can be detected.
+func structParams(v reflect.Value, out map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				structParams(fv, out)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		switch fv.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			if fv.IsNil() {
+				continue
+			}
+		}
+		if strings.Contains(opts, "omitempty") && fv.IsZero() {
+			continue
+		}
+
+		out[name] = fv.Interface()
+	}
+}
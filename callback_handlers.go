@@ -1,34 +1,134 @@
 package tgx
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/harshyadavone/tgx/models"
 )
 
+// callbackRoute matches callback data that isn't routed by exact match.
+type callbackRoute struct {
+	prefix  string         // set for prefix routes
+	re      *regexp.Regexp // set for pattern and regex routes
+	handler callbackHandler
+}
+
+// patternParam matches a {name} placeholder in a callback pattern.
+var patternParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// OnCallback handles callback queries whose data equals data. The data may
+// also be a pattern with {name} placeholders, like "item:{id}:edit", in which
+// case the matched parts are available through ctx.Param("id"). Exact
+// matches take precedence over patterns, and patterns, prefixes and regular
+// expressions are tried in the order they were registered.
 func (b *Bot) OnCallback(data string, handler callbackHandler) {
-	b.callbackHandlers[data] = handler
+	b.addCallback(data, withMiddleware(b, handler))
 }
 
-func (b *Bot) handleCallbackQuery(cb *models.CallbackQuery) error {
-	ctx := &CallbackContext{
-		QueryID:  cb.ID,
-		Data:     cb.Data,
-		Message:  cb.Message,
-		UserID:   cb.From.Id,
-		Username: cb.From.Username,
-		bot:      b,
+func (b *Bot) addCallback(data string, handler callbackHandler) {
+	if !patternParam.MatchString(data) {
+		b.callbackHandlers[data] = handler
+		return
+	}
+	b.callbackRoutes = append(b.callbackRoutes, callbackRoute{re: compileCallbackPattern(data), handler: handler})
+}
+
+// OnCallbackPrefix handles callback queries whose data starts with prefix.
+// The rest of the data is available through ctx.Param("rest").
+func (b *Bot) OnCallbackPrefix(prefix string, handler callbackHandler) {
+	b.callbackRoutes = append(b.callbackRoutes, callbackRoute{prefix: prefix, handler: withMiddleware(b, handler)})
+}
+
+// OnCallbackRegex handles callback queries whose data matches re. Named
+// groups are available through ctx.Param.
+func (b *Bot) OnCallbackRegex(re *regexp.Regexp, handler callbackHandler) {
+	b.callbackRoutes = append(b.callbackRoutes, callbackRoute{re: re, handler: withMiddleware(b, handler)})
+}
+
+// OnUnknownCallback handles callback queries that match no other route.
+func (b *Bot) OnUnknownCallback(handler callbackHandler) {
+	b.unknownCallbackHandler = withMiddleware(b, handler)
+}
+
+// compileCallbackPattern turns "item:{id}:edit" into ^item:(?P<id>.+?):edit$.
+func compileCallbackPattern(pattern string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("^")
+	last := 0
+	for _, loc := range patternParam.FindAllStringSubmatchIndex(pattern, -1) {
+		expr.WriteString(regexp.QuoteMeta(pattern[last:loc[0]]))
+		fmt.Fprintf(&expr, "(?P<%s>.+?)", pattern[loc[2]:loc[3]])
+		last = loc[1]
 	}
+	expr.WriteString(regexp.QuoteMeta(pattern[last:]))
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
 
-	if handler, ok := b.callbackHandlers[cb.Data]; ok {
-		ctx.bot.logger.Debug("callback handler called")
-		if err := handler(ctx); err != nil {
-			ctx.bot.logger.Error("error in calling handler %w: ", err)
-			return err
+// matchCallback returns the handler for data and the parameters it extracted.
+func (b *Bot) matchCallback(data string) (callbackHandler, map[string]string) {
+	if handler, ok := b.callbackHandlers[data]; ok {
+		return handler, nil
+	}
+
+	for _, route := range b.callbackRoutes {
+		if route.re == nil {
+			if rest, ok := strings.CutPrefix(data, route.prefix); ok {
+				return route.handler, map[string]string{"rest": rest}
+			}
+			continue
+		}
+
+		match := route.re.FindStringSubmatch(data)
+		if match == nil {
+			continue
+		}
+		params := make(map[string]string)
+		for i, name := range route.re.SubexpNames() {
+			if name != "" {
+				params[name] = match[i]
+			}
 		}
+		return route.handler, params
+	}
+
+	return b.unknownCallbackHandler, nil
+}
+
+func (b *Bot) handleCallbackQuery(base handlerContext, cb *models.CallbackQuery) error {
+	ctx := &CallbackContext{
+		QueryID:        cb.ID,
+		Data:           cb.Data,
+		Message:        cb.Message,
+		UserID:         cb.From.Id,
+		Username:       cb.From.Username,
+		handlerContext: base,
+	}
+
+	handler, params := b.matchCallback(cb.Data)
+	if handler == nil {
+		b.logger.Debug("No callback handler for %q", cb.Data)
+		return nil
+	}
+	ctx.params = params
+
+	ctx.bot.logger.Debug("callback handler called")
+	if err := handler(ctx); err != nil {
+		ctx.bot.logger.Error("error in calling handler %w: ", err)
+		return err
 	}
 
 	return nil
 }
 
+// Param returns a parameter extracted from the callback data by the route
+// that matched it, or "" if there is none.
+func (ctx *CallbackContext) Param(name string) string {
+	return ctx.params[name]
+}
+
 func (ctx *CallbackContext) AnswerCallback(opts *CallbackAnswerOptions) error {
 	payload := map[string]interface{}{
 		"callback_query_id": ctx.QueryID,
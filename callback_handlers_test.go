@@ -0,0 +1,83 @@
+package tgx
+
+import (
+	"maps"
+	"regexp"
+	"testing"
+)
+
+func TestCompileCallbackPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		data    string
+		params  map[string]string // nil when data doesn't match
+	}{
+		{pattern: "item:{id}:edit", data: "item:42:edit", params: map[string]string{"id": "42"}},
+		{pattern: "item:{id}:edit", data: "item::edit", params: nil},
+		{pattern: "item:{id}:edit", data: "item:42:edit:more", params: nil},
+		{pattern: "item:{id}:edit", data: "xitem:42:edit", params: nil},
+		{pattern: "item:{id}", data: "item:4:2", params: map[string]string{"id": "4:2"}},
+		{pattern: "{a}:{b}", data: "x:y:z", params: map[string]string{"a": "x", "b": "y:z"}},
+		{pattern: "page.{n}", data: "page.3", params: map[string]string{"n": "3"}},
+		{pattern: "page.{n}", data: "pageX3", params: nil},
+		{pattern: "(vote)+{choice}", data: "(vote)+yes", params: map[string]string{"choice": "yes"}},
+	}
+
+	for _, tt := range tests {
+		re := compileCallbackPattern(tt.pattern)
+		got := matchParams(re, tt.data)
+		if !maps.Equal(got, tt.params) || (got == nil) != (tt.params == nil) {
+			t.Errorf("pattern %q on %q: got %v, want %v", tt.pattern, tt.data, got, tt.params)
+		}
+	}
+}
+
+func TestMatchCallback(t *testing.T) {
+	b := &Bot{callbackHandlers: make(map[string]callbackHandler)}
+	route := func(name string) callbackHandler {
+		return func(ctx *CallbackContext) error {
+			ctx.Data = name
+			return nil
+		}
+	}
+	b.OnCallback("item:new", route("exact"))
+	b.OnCallback("item:{id}", route("pattern"))
+	b.OnCallbackPrefix("item:", route("prefix"))
+	b.OnCallbackRegex(regexp.MustCompile(`^del:(?P<id>\d+)$`), route("regex"))
+	b.OnUnknownCallback(route("unknown"))
+
+	tests := []struct {
+		data   string
+		route  string
+		params map[string]string
+	}{
+		{data: "item:new", route: "exact"},
+		{data: "item:7", route: "pattern", params: map[string]string{"id": "7"}},
+		{data: "item:", route: "prefix", params: map[string]string{"rest": ""}},
+		{data: "del:9", route: "regex", params: map[string]string{"id": "9"}},
+		{data: "del:x", route: "unknown"},
+	}
+
+	for _, tt := range tests {
+		handler, params := b.matchCallback(tt.data)
+		ctx := &CallbackContext{}
+		handler(ctx)
+		if ctx.Data != tt.route || !maps.Equal(params, tt.params) {
+			t.Errorf("matchCallback(%q) = %s %v, want %s %v", tt.data, ctx.Data, params, tt.route, tt.params)
+		}
+	}
+}
+
+func matchParams(re *regexp.Regexp, data string) map[string]string {
+	match := re.FindStringSubmatch(data)
+	if match == nil {
+		return nil
+	}
+	params := make(map[string]string)
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			params[name] = match[i]
+		}
+	}
+	return params
+}
@@ -0,0 +1,53 @@
+package tgx
+
+import (
+	"github.com/harshyadavone/tgx/models"
+)
+
+type ChatMemberHandler func(ctx *ChatMemberContext) error
+
+// OnMyChatMember handles changes of the bot's own status in a chat: being
+// added to or removed from a group, promoted, or blocked in a private chat.
+func (b *Bot) OnMyChatMember(handler ChatMemberHandler) {
+	b.myChatMemberHandler = withMiddleware(b, handler)
+}
+
+// OnChatMember handles status changes of other members in chats where the
+// bot is an administrator. Telegram only sends them when "chat_member" is
+// listed in the allowed updates of the webhook or of StartPolling.
+func (b *Bot) OnChatMember(handler ChatMemberHandler) {
+	b.chatMemberHandler = withMiddleware(b, handler)
+}
+
+func (b *Bot) handleChatMember(base handlerContext, update *models.ChatMemberUpdated, handler ChatMemberHandler) error {
+	if handler == nil {
+		return nil
+	}
+
+	ctx := &ChatMemberContext{
+		Member:         update,
+		ChatID:         update.Chat.Id,
+		UserID:         update.NewChatMember.GetUser().Id,
+		Username:       update.NewChatMember.GetUser().Username,
+		handlerContext: base,
+	}
+	return handler(ctx)
+}
+
+// IsJoin reports whether the member joined the chat.
+func (ctx *ChatMemberContext) IsJoin() bool {
+	return ctx.Member.IsJoin()
+}
+
+// IsLeave reports whether the member left the chat or was removed from it.
+func (ctx *ChatMemberContext) IsLeave() bool {
+	return ctx.Member.IsLeave()
+}
+
+// Send sends a message to the chat the change happened in.
+func (ctx *ChatMemberContext) Send(text string) error {
+	return ctx.bot.makeAPIRequest(ctx.Context(), "sendMessage", map[string]interface{}{
+		"chat_id": ctx.ChatID,
+		"text":    text,
+	})
+}
@@ -1,5 +1,93 @@
 package tgx
 
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// OnCommand handles /command messages. Commands are matched case-insensitively,
+// and "/command@username" only when username is the bot's own.
 func (b *Bot) OnCommand(command string, handler Handler) {
-	b.commandHandler[command] = handler
+	b.messages.commands[strings.ToLower(command)] = withMiddleware(b, handler)
+}
+
+// OnUnknownCommand handles commands that have no handler of their own.
+// ctx.Command holds the command that was sent.
+func (b *Bot) OnUnknownCommand(handler Handler) {
+	b.messages.unknownCommand = withMiddleware(b, handler)
+}
+
+// parseCommand splits a command message into the command, the username it is
+// addressed to, if any, and the text of its arguments.
+func parseCommand(text string) (command, mention, rawArgs string, ok bool) {
+	if !strings.HasPrefix(text, "/") {
+		return "", "", "", false
+	}
+
+	end := strings.IndexFunc(text, unicode.IsSpace)
+	if end < 0 {
+		end = len(text)
+	}
+	command, mention, _ = strings.Cut(text[1:end], "@")
+	if command == "" {
+		return "", "", "", false
+	}
+	return command, mention, strings.TrimSpace(text[end:]), true
+}
+
+// splitArgs splits command arguments on whitespace. Single and double quotes
+// group words into one argument and a backslash escapes the next character.
+func splitArgs(s string) []string {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
+// botUsername returns the bot's username, asking Telegram the first time.
+func (b *Bot) botUsername(ctx context.Context) (string, error) {
+	b.usernameMu.Lock()
+	defer b.usernameMu.Unlock()
+
+	if b.username == "" {
+		me, err := b.GetMeCtx(ctx)
+		if err != nil {
+			return "", err
+		}
+		b.username = me.Username
+	}
+	return b.username, nil
 }
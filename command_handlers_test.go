@@ -0,0 +1,96 @@
+package tgx
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/harshyadavone/tgx/models"
+	"github.com/harshyadavone/tgx/pkg/logger"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text    string
+		command string
+		mention string
+		rawArgs string
+		ok      bool
+	}{
+		{text: "/start", command: "start", ok: true},
+		{text: "/Start@MyBot", command: "Start", mention: "MyBot", ok: true},
+		{text: "/ban@MyBot  42   spam ", command: "ban", mention: "MyBot", rawArgs: "42   spam", ok: true},
+		{text: "/note\nfirst line", command: "note", rawArgs: "first line", ok: true},
+		{text: "/say\t\"hi there\"", command: "say", rawArgs: "\"hi there\"", ok: true},
+		{text: "hello", ok: false},
+		{text: "", ok: false},
+		{text: "/", ok: false},
+		{text: "/ start", ok: false},
+		{text: "/@MyBot", ok: false},
+	}
+
+	for _, tt := range tests {
+		command, mention, rawArgs, ok := parseCommand(tt.text)
+		if command != tt.command || mention != tt.mention || rawArgs != tt.rawArgs || ok != tt.ok {
+			t.Errorf("parseCommand(%q) = %q, %q, %q, %v; want %q, %q, %q, %v",
+				tt.text, command, mention, rawArgs, ok, tt.command, tt.mention, tt.rawArgs, tt.ok)
+		}
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "", want: nil},
+		{in: "   ", want: nil},
+		{in: "a b c", want: []string{"a", "b", "c"}},
+		{in: "  a   b\t\nc  ", want: []string{"a", "b", "c"}},
+		{in: `"hello world" x`, want: []string{"hello world", "x"}},
+		{in: `'it''s' x`, want: []string{"its", "x"}},
+		{in: `"it's" 'say "hi"'`, want: []string{"it's", `say "hi"`}},
+		{in: `a\ b c`, want: []string{"a b", "c"}},
+		{in: `"a \"quoted\" word"`, want: []string{`a "quoted" word`}},
+		{in: `'no \escapes'`, want: []string{`no \escapes`}},
+		{in: `"" x`, want: []string{"", "x"}},
+		{in: `key="some value"`, want: []string{"key=some value"}},
+		{in: `"unterminated quote`, want: []string{"unterminated quote"}},
+		{in: `trailing\`, want: []string{"trailing"}},
+		{in: "ünï cödé", want: []string{"ünï", "cödé"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitArgs(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCommandRouting(t *testing.T) {
+	b := NewBot("1:token", "", logger.NewDefaultLogger(logger.ERROR))
+	b.username = "MyBot" // skip GetMe
+
+	var got []string
+	b.OnCommand("Start", func(ctx *Context) error {
+		got = append(got, "start "+ctx.RawArgs)
+		return nil
+	})
+	b.OnUnknownCommand(func(ctx *Context) error {
+		got = append(got, "unknown "+ctx.Command)
+		return nil
+	})
+
+	for _, text := range []string{"/start", "/START@mybot a  b", "/start@OtherBot", "/help x"} {
+		message := &models.Message{Text: text}
+		base := handlerContext{bot: b, reqCtx: context.Background(), update: &models.Update{Message: message}}
+		if err := b.handleMessageUpdate(base, message, &b.messages); err != nil {
+			t.Errorf("handling %q: %v", text, err)
+		}
+	}
+
+	want := []string{"start ", "start a  b", "unknown help"}
+	if !slices.Equal(got, want) {
+		t.Errorf("handled %q, want %q", got, want)
+	}
+}
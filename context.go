@@ -1,8 +1,14 @@
 package tgx
 
-import "github.com/harshyadavone/tgx/models"
+import (
+	"context"
+	"regexp"
+
+	"github.com/harshyadavone/tgx/models"
+)
 
 type Context struct {
+	handlerContext
 	Text      string
 	Photo     []*models.PhotoSize
 	Video     *models.Video
@@ -12,19 +18,87 @@ type Context struct {
 	Animation *models.Animation
 	Audio     *models.Audio
 	VideoNote *models.VideoNote
-	Args      []string
+	Command   string   // Command without the slash and @username, as sent
+	Args      []string // Arguments of the command, split like a shell would
+	RawArgs   string   // Arguments of the command as sent
+	Matches   []string // Text and capture groups matched by an OnText or OnHears route
 	UserID    int64
 	Username  string
 	MessageId int64
 	ChatID    int64
-	bot       *Bot
+	Message   *models.Message // The message, edited message or channel post being handled
+	textRoute *regexp.Regexp  // set when an OnText or OnHears route matched
 }
 
 type CallbackContext struct {
+	handlerContext
 	QueryID  string
 	Data     string
 	Message  *models.Message
 	UserID   int64
 	Username string
-	bot      *Bot
+	params   map[string]string
+}
+
+type InlineQueryContext struct {
+	handlerContext
+	QueryID  string
+	Query    string
+	Offset   string // Offset of the results to return, the NextOffset of the previous answer
+	ChatType string
+	UserID   int64
+	Username string
+}
+
+type ChosenInlineResultContext struct {
+	handlerContext
+	ResultID        string
+	Query           string
+	InlineMessageID string
+	UserID          int64
+	Username        string
+}
+
+type ChatMemberContext struct {
+	handlerContext
+	Member   *models.ChatMemberUpdated
+	ChatID   int64
+	UserID   int64 // The member whose status changed
+	Username string
+}
+
+// UpdateContext is what every handler context has in common. Middleware
+// receives handler contexts as UpdateContext and can type switch on them to
+// reach the fields of a particular update kind.
+type UpdateContext interface {
+	Context() context.Context
+	Update() *models.Update
+	Bot() *Bot
+}
+
+// handlerContext is embedded in every handler context.
+type handlerContext struct {
+	bot    *Bot
+	reqCtx context.Context
+	update *models.Update
+}
+
+// Context returns the request-scoped context of the update being handled.
+// It carries the values of the webhook request and is cancelled when the
+// handler deadline set with WithHandlerTimeout expires.
+func (c *handlerContext) Context() context.Context {
+	if c.reqCtx == nil {
+		return context.Background()
+	}
+	return c.reqCtx
+}
+
+// Update returns the raw update being handled.
+func (c *handlerContext) Update() *models.Update {
+	return c.update
+}
+
+// Bot returns the bot that received the update.
+func (c *handlerContext) Bot() *Bot {
+	return c.bot
 }
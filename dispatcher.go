@@ -0,0 +1,523 @@
+package tgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/harshyadavone/tgx/models"
+)
+
+// QueuePolicy decides what happens to an update when the worker pool queue is full.
+type QueuePolicy int
+
+const (
+	// QueueBlock waits for room in the queue. Webhook requests stay open and
+	// polling stops fetching until a worker frees up.
+	QueueBlock QueuePolicy = iota
+	// QueueDrop discards the update and acknowledges it anyway.
+	QueueDrop
+	// QueueReject answers webhook requests with 503 so Telegram redelivers the
+	// update later. Polling has no way to refuse an update and blocks instead.
+	QueueReject
+)
+
+// WorkerPool bounds how many updates are handled at once.
+type WorkerPool struct {
+	Workers int // Handlers running concurrently
+	// QueueSize bounds the updates waiting for a worker; zero hands updates
+	// straight to idle workers. With WithOrderedUpdates it separately bounds
+	// the updates waiting behind an update of the same key, to at least one.
+	QueueSize int
+	Policy    QueuePolicy // What to do when the queue is full
+}
+
+// WithWorkerPool handles updates on a fixed number of workers fed by a bounded
+// queue. Without it every update gets its own goroutine.
+func WithWorkerPool(pool WorkerPool) Option {
+	return func(b *Bot) {
+		b.pool = pool
+	}
+}
+
+// UpdateKey groups updates that must be handled one after another.
+// It returns false for updates that can be handled in any order.
+type UpdateKey func(update *models.Update) (int64, bool)
+
+// ByChat orders the updates of each chat.
+func ByChat(update *models.Update) (int64, bool) {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.Id, true
+	case update.EditedMessage != nil:
+		return update.EditedMessage.Chat.Id, true
+	case update.ChannelPost != nil:
+		return update.ChannelPost.Chat.Id, true
+	case update.EditedChannelPost != nil:
+		return update.EditedChannelPost.Chat.Id, true
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.Id, true
+	case update.MyChatMember != nil:
+		return update.MyChatMember.Chat.Id, true
+	case update.ChatMember != nil:
+		return update.ChatMember.Chat.Id, true
+	}
+	return 0, false
+}
+
+// ByUser orders the updates sent by each user.
+func ByUser(update *models.Update) (int64, bool) {
+	switch {
+	case update.Message != nil:
+		return update.Message.From.Id, true
+	case update.EditedMessage != nil:
+		return update.EditedMessage.From.Id, true
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.Id, true
+	case update.InlineQuery != nil:
+		return update.InlineQuery.From.Id, true
+	case update.ChosenInlineResult != nil:
+		return update.ChosenInlineResult.From.Id, true
+	case update.MyChatMember != nil:
+		return update.MyChatMember.From.Id, true
+	case update.ChatMember != nil:
+		return update.ChatMember.From.Id, true
+	}
+	return 0, false
+}
+
+// WithOrderedUpdates handles updates with the same key strictly in the order
+// they were received, e.g. WithOrderedUpdates(tgx.ByChat). Updates with
+// different keys still run concurrently. An update waiting behind a busy key
+// doesn't take up a worker; with a worker pool, the updates waiting behind
+// busy keys are bounded by its QueueSize and its Policy applies to them.
+func WithOrderedUpdates(key UpdateKey) Option {
+	return func(b *Bot) {
+		b.orderKey = key
+	}
+}
+
+var (
+	// errQueueFull is returned by dispatch when an update was not queued.
+	errQueueFull = errors.New("update queue is full")
+	// errShuttingDown is returned by dispatch once Shutdown has been called.
+	errShuttingDown = errors.New("bot is shutting down")
+	// errNotPersisted is returned by dispatch when the update log can't store an update.
+	errNotPersisted = errors.New("failed to persist update")
+)
+
+type job struct {
+	ctx     context.Context
+	update  *models.Update
+	key     int64
+	ordered bool
+	logID   int64 // id in the update log, zero without one
+	waits   bool  // holds a slot of dispatcher.waiting
+	retries int   // failed attempts made by this run
+}
+
+// dispatcher hands updates to processUpdate, either on a goroutine per update
+// or through the worker pool.
+type dispatcher struct {
+	bot      *Bot
+	policy   QueuePolicy
+	queue    chan job // nil without a worker pool
+	orderKey UpdateKey
+
+	// lanes holds the updates waiting for the update of the same key that is
+	// being handled. A key is present while one of its updates is in flight.
+	mu     sync.Mutex
+	lanes  map[int64][]job
+	closed bool
+	// waiting holds a slot for every update waiting in a lane, bounding them
+	// like the queue bounds the updates waiting for a worker. nil without a
+	// worker pool.
+	waiting chan struct{}
+
+	handlers tracker // accepted updates that haven't been handled yet
+	quit     chan struct{}
+	stopOnce sync.Once
+}
+
+func newDispatcher(b *Bot, pool WorkerPool, orderKey UpdateKey) *dispatcher {
+	d := &dispatcher{
+		bot:      b,
+		policy:   pool.Policy,
+		orderKey: orderKey,
+		lanes:    make(map[int64][]job),
+		quit:     make(chan struct{}),
+	}
+	if pool.Workers <= 0 {
+		return d
+	}
+
+	d.queue = make(chan job, max(pool.QueueSize, 0))
+	d.waiting = make(chan struct{}, max(pool.QueueSize, 1))
+	for i := 0; i < pool.Workers; i++ {
+		go d.work()
+	}
+	return d
+}
+
+func (d *dispatcher) work() {
+	for {
+		select {
+		case j := <-d.queue:
+			d.run(j)
+		case <-d.quit:
+			return
+		}
+	}
+}
+
+// run handles j and then every update that queued up behind it in its lane.
+func (d *dispatcher) run(j job) {
+	for {
+		if d.process(j) {
+			// Scheduled to be tried again: j keeps its lane meanwhile.
+			return
+		}
+		d.handlers.done()
+		if !j.ordered {
+			return
+		}
+
+		next, ok := d.next(j.key)
+		if !ok {
+			return
+		}
+		j = next
+	}
+}
+
+// Delays between the attempts at handling an update from the update log.
+const (
+	minRetryDelay = time.Second
+	maxRetryDelay = 30 * time.Second
+)
+
+// retryingKey marks the context of an attempt that is followed by another
+// one if it fails, so that the error handler isn't called for it.
+type retryingKey struct{}
+
+// willRetry reports whether the update handled under ctx is tried again if
+// its handler fails.
+func willRetry(ctx context.Context) bool {
+	retrying, _ := ctx.Value(retryingKey{}).(bool)
+	return retrying
+}
+
+// process handles j. With an update log, every attempt is recorded and the
+// update is marked done once it succeeds. A handler that returns an error or
+// panics is a failed attempt, unless the error is permanent: the update is
+// tried again after a growing delay, until it runs out of attempts and is
+// dead-lettered. process reports whether j was scheduled to be tried again.
+func (d *dispatcher) process(j job) bool {
+	log := d.bot.updateLog
+	if log == nil || j.logID == 0 {
+		d.bot.processUpdate(j.ctx, j.update)
+		return false
+	}
+
+	ok, err := log.start(j.logID)
+	if errors.Is(err, errDeadLettered) {
+		d.bot.logger.Error("Update %d failed too many times: %v", j.update.UpdateId, err)
+		return false
+	}
+	if err != nil {
+		d.bot.logger.Error("Failed to record update %d in the update log: %v", j.update.UpdateId, err)
+	}
+	if !ok {
+		return false
+	}
+
+	ctx := j.ctx
+	last := log.exhausted(j.logID)
+	if !last {
+		ctx = context.WithValue(ctx, retryingKey{}, true)
+	}
+	if err := d.bot.processUpdate(ctx, j.update); err != nil && !permanent(err) {
+		if !last {
+			d.retry(j)
+			return true
+		}
+		// Out of attempts: start moves it to the dead-letter file.
+		if _, err := log.start(j.logID); !errors.Is(err, errDeadLettered) {
+			d.bot.logger.Error("Failed to dead-letter update %d: %v", j.update.UpdateId, err)
+		}
+		d.bot.logger.Error("Update %d failed too many times: %v", j.update.UpdateId, err)
+		return false
+	}
+	if err := log.finish(j.logID); err != nil {
+		d.bot.logger.Error("Failed to record update %d in the update log: %v", j.update.UpdateId, err)
+	}
+	return false
+}
+
+// retry queues j again once a delay growing with every attempt has passed,
+// without holding a worker in the meantime. Shutdown cancels the retry and
+// leaves the update in the log for the next run.
+func (d *dispatcher) retry(j job) {
+	j.retries++
+	delay := min(minRetryDelay<<min(j.retries-1, 5), maxRetryDelay)
+	d.bot.logger.Warn("Handling update %d failed, retrying in %s", j.update.UpdateId, delay)
+
+	go func() {
+		if !sleepCtx(d.bot.done, delay) {
+			d.bot.logger.Warn("Update %d left in the update log for the next run", j.update.UpdateId)
+			d.handlers.done()
+			if j.ordered {
+				d.abandon(j.key)
+			}
+			return
+		}
+		d.enqueue(context.Background(), j, true)
+	}()
+}
+
+// permanent reports whether handling the update again would fail the same
+// way: it had no handler, or Telegram refused a request for another reason
+// than flood control, like the user having blocked the bot.
+func permanent(err error) bool {
+	if noHandler(err) {
+		return true
+	}
+	var botErr *BotError
+	var apiErr *APIError
+	if errors.As(err, &botErr) && errors.As(botErr.Err, &apiErr) {
+		return apiErr.Code >= 400 && apiErr.Code < 500 && apiErr.Code != http.StatusTooManyRequests
+	}
+	return false
+}
+
+// noHandler reports whether err means the update had no handler to run.
+func noHandler(err error) bool {
+	var botErr *BotError
+	return errors.As(err, &botErr) && errors.Is(botErr.Err, errNoHandler)
+}
+
+// next pops the next update of key's lane, releasing the lane when it is empty.
+func (d *dispatcher) next(key int64) (job, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	pending := d.lanes[key]
+	if len(pending) == 0 {
+		delete(d.lanes, key)
+		return job{}, false
+	}
+	d.lanes[key] = pending[1:]
+	if pending[0].waits {
+		<-d.waiting
+	}
+	return pending[0], true
+}
+
+// claim claims key's lane for j. It returns false if another update of the
+// same key is in flight, in which case j has been queued behind it.
+func (d *dispatcher) claim(j job) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if pending, busy := d.lanes[j.key]; busy {
+		d.lanes[j.key] = append(pending, j)
+		return false
+	}
+	d.lanes[j.key] = nil
+	return true
+}
+
+// enter is claim for new updates: waiting in a lane takes a slot, which is
+// waited for or refused according to the queue policy.
+func (d *dispatcher) enter(ctx context.Context, j job, block bool) (bool, error) {
+	d.mu.Lock()
+	if _, busy := d.lanes[j.key]; !busy {
+		d.lanes[j.key] = nil
+		d.mu.Unlock()
+		return true, nil
+	}
+	d.mu.Unlock()
+
+	if d.waiting != nil {
+		if err := send(ctx, d.waiting, struct{}{}, d.policy == QueueBlock || block); err != nil {
+			return false, err
+		}
+		j.waits = true
+	}
+	if d.claim(j) {
+		// The lane was released in the meantime.
+		if j.waits {
+			<-d.waiting
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// abandon gives up key's lane after its first update could not be queued.
+// Updates that queued up behind it in the meantime still run, in order.
+func (d *dispatcher) abandon(key int64) {
+	if next, ok := d.next(key); ok {
+		go d.enqueue(context.Background(), next, true)
+	}
+}
+
+// dispatch queues update for handling. ctx bounds how long QueueBlock waits
+// for room; the handler keeps its values but not its cancellation, as it
+// outlives the webhook request. block forces waiting whatever the policy.
+func (d *dispatcher) dispatch(ctx context.Context, update *models.Update, block bool) error {
+	if !d.accept() {
+		return errShuttingDown
+	}
+
+	j := job{ctx: context.WithoutCancel(ctx), update: update}
+	if log := d.bot.updateLog; log != nil {
+		id, err := log.add(update)
+		if err != nil {
+			d.handlers.done()
+			return fmt.Errorf("%w: %w", errNotPersisted, err)
+		}
+		j.logID = id
+	}
+
+	return d.submit(ctx, j, block)
+}
+
+// submit hands an accepted job to its lane or the queue.
+func (d *dispatcher) submit(ctx context.Context, j job, block bool) error {
+	if d.orderKey != nil {
+		j.key, j.ordered = d.orderKey(j.update)
+	}
+	if j.ordered {
+		head, err := d.enter(ctx, j, block)
+		if err != nil {
+			d.refuse(j)
+			return err
+		}
+		if !head {
+			return nil
+		}
+	}
+
+	err := d.enqueue(ctx, j, block)
+	if err != nil {
+		d.refuse(j)
+		if j.ordered {
+			d.abandon(j.key)
+		}
+	}
+	return err
+}
+
+// refuse forgets an accepted job that could not be queued.
+func (d *dispatcher) refuse(j job) {
+	if j.logID != 0 {
+		// Dropped or left for Telegram to deliver again.
+		d.bot.updateLog.finish(j.logID)
+	}
+	d.handlers.done()
+}
+
+// replay dispatches the updates the update log holds from an earlier run.
+// Their lanes are claimed right away, so that new updates of the same key
+// wait for them, but they are queued for the workers in the background.
+func (d *dispatcher) replay() {
+	log := d.bot.updateLog
+	if log == nil {
+		return
+	}
+
+	ids, updates := log.unfinished()
+	if len(ids) == 0 {
+		return
+	}
+	d.bot.logger.Info("Replaying %d unfinished updates", len(ids))
+
+	var heads []job
+	for _, id := range ids {
+		if !d.accept() {
+			break
+		}
+		j := job{ctx: context.Background(), update: updates[id], logID: id}
+		if d.orderKey != nil {
+			j.key, j.ordered = d.orderKey(j.update)
+			// The backlog is never refused, so it doesn't take lane slots.
+			if j.ordered && !d.claim(j) {
+				continue
+			}
+		}
+		heads = append(heads, j)
+	}
+
+	go func() {
+		for _, j := range heads {
+			// Blocking without a deadline: enqueue can't fail.
+			d.enqueue(context.Background(), j, true)
+		}
+	}()
+}
+
+// accept counts a new update as in flight unless the dispatcher is closed.
+func (d *dispatcher) accept() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return false
+	}
+	d.handlers.add()
+	return true
+}
+
+// shutdown refuses new updates and waits for the accepted ones to be handled.
+// The workers are stopped once nothing is left for them to do.
+func (d *dispatcher) shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+
+	if err := d.handlers.wait(ctx); err != nil {
+		return err
+	}
+	d.stopOnce.Do(func() { close(d.quit) })
+	return nil
+}
+
+func (d *dispatcher) enqueue(ctx context.Context, j job, block bool) error {
+	if d.queue == nil {
+		go d.run(j)
+		return nil
+	}
+
+	return send(ctx, d.queue, j, d.policy == QueueBlock || block)
+}
+
+// send sends v on ch. If ch has no room it waits when wait is set and
+// returns errQueueFull otherwise.
+func send[T any](ctx context.Context, ch chan<- T, v T, wait bool) error {
+	if wait {
+		select {
+		case ch <- v:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	select {
+	case ch <- v:
+		return nil
+	default:
+		return errQueueFull
+	}
+}
+
+// QueueDepth returns the number of updates waiting for a worker or, with
+// WithOrderedUpdates, behind an update of the same key.
+// It is always zero without a worker pool.
+func (b *Bot) QueueDepth() int {
+	return len(b.dispatcher.queue) + len(b.dispatcher.waiting)
+}
@@ -0,0 +1,153 @@
+package tgx
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/harshyadavone/tgx/models"
+	"github.com/harshyadavone/tgx/pkg/logger"
+)
+
+func TestOrderedUpdates(t *testing.T) {
+	pools := map[string]WorkerPool{
+		"goroutine per update": {},
+		"worker pool":          {Workers: 4, QueueSize: 2},
+		"unbuffered pool":      {Workers: 2},
+	}
+
+	for name, pool := range pools {
+		t.Run(name, func(t *testing.T) {
+			b := NewBot("1:token", "", logger.NewDefaultLogger(logger.ERROR),
+				WithWorkerPool(pool), WithOrderedUpdates(ByChat))
+
+			var mu sync.Mutex
+			handled := make(map[int64][]int)
+			inFlight := make(map[int64]int)
+			b.OnMessage("Text", func(ctx *Context) error {
+				mu.Lock()
+				inFlight[ctx.ChatID]++
+				if inFlight[ctx.ChatID] > 1 {
+					t.Errorf("chat %d has %d updates in flight", ctx.ChatID, inFlight[ctx.ChatID])
+				}
+				mu.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				inFlight[ctx.ChatID]--
+				handled[ctx.ChatID] = append(handled[ctx.ChatID], int(ctx.MessageId))
+				mu.Unlock()
+				return nil
+			})
+
+			const chats, perChat = 3, 20
+			want := make(map[int64][]int)
+			for i := 0; i < chats*perChat; i++ {
+				chat := int64(i % chats)
+				update := &models.Update{
+					UpdateId: i,
+					Message:  &models.Message{MessageId: int64(i), Chat: models.Chat{Id: chat}, Text: "x"},
+				}
+				if err := b.dispatcher.dispatch(context.Background(), update, false); err != nil {
+					t.Fatalf("dispatch update %d: %v", i, err)
+				}
+				want[chat] = append(want[chat], i)
+			}
+
+			if err := b.Shutdown(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			for chat, ids := range want {
+				if !slices.Equal(handled[chat], ids) {
+					t.Errorf("chat %d handled %v, want %v", chat, handled[chat], ids)
+				}
+			}
+		})
+	}
+}
+
+func TestUnorderedUpdatesSkipLanes(t *testing.T) {
+	b := NewBot("1:token", "", logger.NewDefaultLogger(logger.ERROR),
+		WithWorkerPool(WorkerPool{Workers: 2}), WithOrderedUpdates(ByChat))
+
+	// Inline queries have no chat, so both can be in flight at once.
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	b.OnInlineQuery(func(ctx *InlineQueryContext) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+
+	for i := 1; i <= 2; i++ {
+		update := &models.Update{UpdateId: i, InlineQuery: &models.InlineQuery{Id: "q"}}
+		if err := b.dispatcher.dispatch(context.Background(), update, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("unordered updates were not handled concurrently")
+		}
+	}
+	close(release)
+
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLanesCountAgainstQueueSize(t *testing.T) {
+	b := NewBot("1:token", "", logger.NewDefaultLogger(logger.ERROR),
+		WithWorkerPool(WorkerPool{Workers: 1, QueueSize: 1, Policy: QueueReject}),
+		WithOrderedUpdates(ByChat))
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var handled []int
+	b.OnMessage("Text", func(ctx *Context) error {
+		started <- struct{}{}
+		<-release
+		mu.Lock()
+		handled = append(handled, int(ctx.MessageId))
+		mu.Unlock()
+		return nil
+	})
+
+	dispatch := func(id int) error {
+		return b.dispatcher.dispatch(context.Background(), testUpdate(id, "x"), false)
+	}
+	if err := dispatch(1); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	if err := dispatch(2); err != nil {
+		t.Fatalf("waiting behind a busy chat: %v", err)
+	}
+	for id := 3; id <= 10; id++ {
+		if err := dispatch(id); err != errQueueFull {
+			t.Fatalf("dispatch %d with a full lane = %v, want errQueueFull", id, err)
+		}
+	}
+	if got := b.QueueDepth(); got != 1 {
+		t.Errorf("QueueDepth() = %d, want 1", got)
+	}
+
+	close(release)
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 2}; !slices.Equal(handled, want) {
+		t.Errorf("handled %v, want %v", handled, want)
+	}
+	if got := b.QueueDepth(); got != 0 {
+		t.Errorf("QueueDepth() = %d after shutdown, want 0", got)
+	}
+}
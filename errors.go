@@ -16,11 +16,15 @@ func (e *BotError) Error() string {
 }
 
 type APIError struct {
-	Code        int    `json:"error_code"`
-	Description string `json:"description"`
-	Parameters  struct {
-		RetryAfter int `json:"retry_after,omitempty"`
-	} `json:"parameters,omitempty"`
+	Code        int                `json:"error_code"`
+	Description string             `json:"description"`
+	Parameters  ResponseParameters `json:"parameters,omitempty"`
+}
+
+// ResponseParameters describes why a request was unsuccessful.
+type ResponseParameters struct {
+	MigrateToChatId int64 `json:"migrate_to_chat_id,omitempty"` // The group has been migrated to a supergroup with this id
+	RetryAfter      int   `json:"retry_after,omitempty"`        // Seconds left to wait before the request can be repeated
 }
 
 func (e *APIError) Error() string {
@@ -0,0 +1,229 @@
+package tgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/harshyadavone/tgx/models"
+)
+
+// DefaultMaxDownloadSize is the largest file the public Bot API lets bots download.
+const DefaultMaxDownloadSize = 20 << 20
+
+// WithMaxDownloadSize changes the size limit enforced by DownloadFile.
+// A local Bot API server allows much larger files. Zero removes the limit.
+func WithMaxDownloadSize(size int64) Option {
+	return func(b *Bot) {
+		b.maxDownloadSize = size
+	}
+}
+
+func (b *Bot) GetFile(fileID string) (*models.File, error) {
+	return b.GetFileCtx(context.Background(), fileID)
+}
+
+func (b *Bot) GetFileCtx(ctx context.Context, fileID string) (*models.File, error) {
+	return decodeResult[*models.File](b.makeAPIRequestWithResult(ctx, "getFile", map[string]interface{}{
+		"file_id": fileID,
+	}))
+}
+
+// DownloadFile streams the file with the given file_id into w and returns the
+// number of bytes written. A file over the size limit fails the download; if
+// getFile didn't report its size, w holds the part read until then. The transfer is bounded by the upload timeout, or
+// by WithMethodTimeout("downloadFile", ...).
+func (b *Bot) DownloadFile(fileID string, w io.Writer) (int64, error) {
+	return b.DownloadFileCtx(context.Background(), fileID, w)
+}
+
+func (b *Bot) DownloadFileCtx(ctx context.Context, fileID string, w io.Writer) (int64, error) {
+	file, err := b.GetFileCtx(ctx, fileID)
+	if err != nil {
+		return 0, err
+	}
+
+	if b.maxDownloadSize > 0 && file.FileSize > b.maxDownloadSize {
+		return 0, &BotError{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: "File is too big to download",
+			Err:     fmt.Errorf("file %s is %d bytes, limit is %d", fileID, file.FileSize, b.maxDownloadSize),
+		}
+	}
+
+	body, err := b.openFile(ctx, file)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	return b.copyFile(w, body, fileID)
+}
+
+// openFile opens the content of a file returned by getFile. A local Bot API
+// server returns absolute paths, which are read straight from disk.
+func (b *Bot) openFile(ctx context.Context, file *models.File) (io.ReadCloser, error) {
+	if file.FilePath == "" {
+		return nil, &BotError{
+			Code:    http.StatusNotFound,
+			Message: "File is not available for download",
+			Err:     fmt.Errorf("getFile returned no file_path for %s", file.FileId),
+		}
+	}
+
+	if filepath.IsAbs(file.FilePath) {
+		f, err := os.Open(file.FilePath)
+		if err != nil {
+			return nil, &BotError{
+				Code:    http.StatusNotFound,
+				Message: "Failed to open local file",
+				Err:     err,
+			}
+		}
+		return f, nil
+	}
+
+	reqCtx, cancel := withTimeout(ctx, b.requestTimeout("downloadFile", true))
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, b.fileURL(file.FilePath), nil)
+	if err != nil {
+		cancel()
+		return nil, &BotError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to create request",
+			Err:     err,
+		}
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, &BotError{
+			Code:    http.StatusServiceUnavailable,
+			Message: "Failed to download file",
+			Err:     err,
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return nil, &BotError{
+			Code:    resp.StatusCode,
+			Message: "Failed to download file",
+			Err:     fmt.Errorf("unexpected status %s", resp.Status),
+		}
+	}
+
+	return &cancelReadCloser{ReadCloser: resp.Body, cancel: cancel}, nil
+}
+
+// copyFile copies a download into w, failing as soon as it exceeds the size
+// limit. Files whose size getFile didn't report are only caught here, so w may
+// hold up to the limit of partial data when the error is returned.
+func (b *Bot) copyFile(w io.Writer, body io.Reader, fileID string) (int64, error) {
+	if b.maxDownloadSize > 0 {
+		body = &sizeLimitReader{r: body, n: b.maxDownloadSize}
+	}
+
+	n, err := io.Copy(w, body)
+	if errors.Is(err, errFileTooBig) {
+		return n, &BotError{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: "File is too big to download",
+			Err:     fmt.Errorf("file %s exceeds the limit of %d bytes", fileID, b.maxDownloadSize),
+		}
+	}
+	if err != nil {
+		return n, &BotError{
+			Code:    http.StatusServiceUnavailable,
+			Message: "Failed to download file",
+			Err:     err,
+		}
+	}
+	return n, nil
+}
+
+var errFileTooBig = errors.New("file exceeds the download size limit")
+
+// sizeLimitReader reads at most n bytes from r and fails with errFileTooBig
+// if r has more, instead of silently truncating like io.LimitReader.
+type sizeLimitReader struct {
+	r io.Reader
+	n int64 // bytes left
+}
+
+func (l *sizeLimitReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		var probe [1]byte
+		if n, err := l.r.Read(probe[:]); n > 0 {
+			return 0, errFileTooBig
+		} else if err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// cancelReadCloser releases the download's context once the body is closed.
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelReadCloser) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// FileID returns the file_id of the media attached to the message, picking
+// the largest size of a photo, or "" when the message has no file.
+func (ctx *Context) FileID() string {
+	switch {
+	case len(ctx.Photo) > 0:
+		return ctx.Photo[len(ctx.Photo)-1].FileId
+	case ctx.Video != nil:
+		return ctx.Video.FileId
+	case ctx.Voice != nil:
+		return ctx.Voice.FileId
+	case ctx.Document != nil:
+		return ctx.Document.FileId
+	case ctx.Sticker != nil:
+		return ctx.Sticker.FileId
+	case ctx.Animation != nil:
+		return ctx.Animation.FileId
+	case ctx.Audio != nil:
+		return ctx.Audio.FileId
+	case ctx.VideoNote != nil:
+		return ctx.VideoNote.FileId
+	}
+	return ""
+}
+
+// Download streams the media attached to the message into w.
+func (ctx *Context) Download(w io.Writer) (int64, error) {
+	fileID := ctx.FileID()
+	if fileID == "" {
+		return 0, &BotError{
+			Code:    http.StatusBadRequest,
+			Message: "Message has no file to download",
+		}
+	}
+	return ctx.bot.DownloadFileCtx(ctx.Context(), fileID, w)
+}
+
+// DownloadFile streams the file with the given file_id into w.
+func (ctx *Context) DownloadFile(fileID string, w io.Writer) (int64, error) {
+	return ctx.bot.DownloadFileCtx(ctx.Context(), fileID, w)
+}
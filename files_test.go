@@ -0,0 +1,37 @@
+package tgx
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCopyFileSizeLimit(t *testing.T) {
+	b := &Bot{maxDownloadSize: 8}
+
+	tests := []struct {
+		body    string
+		tooBig  bool
+		written int
+	}{
+		{body: "", written: 0},
+		{body: "12345678", written: 8},
+		{body: "123456789", tooBig: true, written: 8},
+		{body: strings.Repeat("x", 1000), tooBig: true, written: 8},
+	}
+
+	for _, tt := range tests {
+		var w bytes.Buffer
+		// One byte per read, so the limit is hit in the middle of the copy.
+		n, err := b.copyFile(&w, iotest.OneByteReader(strings.NewReader(tt.body)), "file")
+		botErr, _ := err.(*BotError)
+		if tt.tooBig != (botErr != nil && botErr.Code == http.StatusRequestEntityTooLarge) || (!tt.tooBig && err != nil) {
+			t.Errorf("%d bytes: err = %v, want too big %v", len(tt.body), err, tt.tooBig)
+		}
+		if int(n) != tt.written || w.Len() != tt.written {
+			t.Errorf("%d bytes: wrote %d (returned %d), want %d", len(tt.body), w.Len(), n, tt.written)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package tgx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/harshyadavone/tgx/models"
+)
+
+type InlineQueryHandler func(ctx *InlineQueryContext) error
+type ChosenInlineResultHandler func(ctx *ChosenInlineResultContext) error
+
+// OnInlineQuery handles inline queries. Inline mode must be enabled for the
+// bot with @BotFather.
+func (b *Bot) OnInlineQuery(handler InlineQueryHandler) {
+	b.inlineQueryHandler = withMiddleware(b, handler)
+}
+
+// OnChosenInlineResult handles the results users pick from the answers to
+// their inline queries. It requires inline feedback to be enabled with @BotFather.
+func (b *Bot) OnChosenInlineResult(handler ChosenInlineResultHandler) {
+	b.chosenInlineResultHandler = withMiddleware(b, handler)
+}
+
+func (b *Bot) handleInlineQuery(base handlerContext, query *models.InlineQuery) error {
+	if b.inlineQueryHandler == nil {
+		return nil
+	}
+
+	ctx := &InlineQueryContext{
+		QueryID:        query.Id,
+		Query:          query.Query,
+		Offset:         query.Offset,
+		ChatType:       query.ChatType,
+		UserID:         query.From.Id,
+		Username:       query.From.Username,
+		handlerContext: base,
+	}
+	return b.inlineQueryHandler(ctx)
+}
+
+func (b *Bot) handleChosenInlineResult(base handlerContext, result *models.ChosenInlineResult) error {
+	if b.chosenInlineResultHandler == nil {
+		return nil
+	}
+
+	ctx := &ChosenInlineResultContext{
+		ResultID:        result.ResultId,
+		Query:           result.Query,
+		InlineMessageID: result.InlineMessageId,
+		UserID:          result.From.Id,
+		Username:        result.From.Username,
+		handlerContext:  base,
+	}
+	return b.chosenInlineResultHandler(ctx)
+}
+
+// Answer sends results for the query. Pass the offset of the next page as
+// nextOffset to have Telegram ask for more when the user scrolls down.
+func (ctx *InlineQueryContext) Answer(results []InlineQueryResult, nextOffset string) error {
+	return ctx.AnswerWithOpts(&AnswerInlineQueryRequest{
+		Results:    results,
+		NextOffset: nextOffset,
+	})
+}
+
+// AnswerWithOpts answers the query with the settings in req. Its
+// InlineQueryId is filled in.
+func (ctx *InlineQueryContext) AnswerWithOpts(req *AnswerInlineQueryRequest) error {
+	answer := *req
+	answer.InlineQueryId = ctx.QueryID
+	return ctx.bot.AnswerInlineQueryCtx(ctx.Context(), &answer)
+}
+
+func (b *Bot) AnswerInlineQuery(req *AnswerInlineQueryRequest) error {
+	return b.AnswerInlineQueryCtx(context.Background(), req)
+}
+
+// isNilResult reports whether result is nil or a nil pointer, such as a nil
+// *InlineQueryResultArticle.
+func isNilResult(result InlineQueryResult) bool {
+	if result == nil {
+		return true
+	}
+	v := reflect.ValueOf(result)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+func (b *Bot) AnswerInlineQueryCtx(ctx context.Context, req *AnswerInlineQueryRequest) error {
+	if req.InlineQueryId == "" {
+		return &BotError{
+			Code:    http.StatusBadRequest,
+			Message: "inline_query_id can't be empty",
+		}
+	}
+
+	results := req.Results
+	if results == nil {
+		results = []InlineQueryResult{}
+	}
+	for i, result := range results {
+		if isNilResult(result) || result.inlineQueryResult().Type == "" {
+			return &BotError{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid inline query result",
+				Err:     fmt.Errorf("result %d is nil or has no type; create it with a New*Result constructor", i),
+			}
+		}
+	}
+
+	payload := map[string]interface{}{
+		"inline_query_id": req.InlineQueryId,
+		"results":         results,
+	}
+	if req.CacheTime > 0 {
+		payload["cache_time"] = req.CacheTime
+	}
+	if req.IsPersonal {
+		payload["is_personal"] = true
+	}
+	if req.NextOffset != "" {
+		payload["next_offset"] = req.NextOffset
+	}
+	if req.Button != nil {
+		payload["button"] = req.Button
+	}
+
+	return b.makeAPIRequest(ctx, "answerInlineQuery", payload)
+}
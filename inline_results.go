@@ -0,0 +1,195 @@
+package tgx
+
+import "github.com/harshyadavone/tgx/models"
+
+// InlineQueryResult is one result of an inline query. Build results with the
+// New*Result constructors, which set the type and the required fields; the
+// optional fields can be set on the returned value.
+type InlineQueryResult interface {
+	inlineQueryResult() *BaseInlineQueryResult
+}
+
+// BaseInlineQueryResult holds the fields shared by every result type.
+type BaseInlineQueryResult struct {
+	Type        string                       `json:"type"` // Set by the constructors
+	ID          string                       `json:"id"`   // Unique within the answer, 1-64 bytes
+	ReplyMarkup *models.InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+}
+
+func (r *BaseInlineQueryResult) inlineQueryResult() *BaseInlineQueryResult {
+	return r
+}
+
+// InputMessageContent is the message sent in place of a result's media.
+// It can be InputTextMessageContent.
+type InputMessageContent interface{}
+
+type InputTextMessageContent struct {
+	MessageText string `json:"message_text"`         // Required
+	ParseMode   string `json:"parse_mode,omitempty"` // MarkdownV2 || HTML
+}
+
+type InlineQueryResultArticle struct {
+	BaseInlineQueryResult
+	Title               string              `json:"title"`                 // Required
+	InputMessageContent InputMessageContent `json:"input_message_content"` // Required
+	URL                 string              `json:"url,omitempty"`
+	Description         string              `json:"description,omitempty"`
+	ThumbnailURL        string              `json:"thumbnail_url,omitempty"`
+}
+
+// NewArticleResult returns a result that sends content when chosen.
+func NewArticleResult(id, title string, content InputMessageContent) *InlineQueryResultArticle {
+	return &InlineQueryResultArticle{
+		BaseInlineQueryResult: BaseInlineQueryResult{Type: "article", ID: id},
+		Title:                 title,
+		InputMessageContent:   content,
+	}
+}
+
+type InlineQueryResultPhoto struct {
+	BaseInlineQueryResult
+	PhotoURL            string              `json:"photo_url"`     // Required, JPEG
+	ThumbnailURL        string              `json:"thumbnail_url"` // Required
+	Title               string              `json:"title,omitempty"`
+	Description         string              `json:"description,omitempty"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+func NewPhotoResult(id, photoURL, thumbnailURL string) *InlineQueryResultPhoto {
+	return &InlineQueryResultPhoto{
+		BaseInlineQueryResult: BaseInlineQueryResult{Type: "photo", ID: id},
+		PhotoURL:              photoURL,
+		ThumbnailURL:          thumbnailURL,
+	}
+}
+
+type InlineQueryResultGif struct {
+	BaseInlineQueryResult
+	GifURL              string              `json:"gif_url"`       // Required
+	ThumbnailURL        string              `json:"thumbnail_url"` // Required
+	Title               string              `json:"title,omitempty"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+func NewGifResult(id, gifURL, thumbnailURL string) *InlineQueryResultGif {
+	return &InlineQueryResultGif{
+		BaseInlineQueryResult: BaseInlineQueryResult{Type: "gif", ID: id},
+		GifURL:                gifURL,
+		ThumbnailURL:          thumbnailURL,
+	}
+}
+
+type InlineQueryResultVideo struct {
+	BaseInlineQueryResult
+	VideoURL            string              `json:"video_url"`     // Required
+	MimeType            string              `json:"mime_type"`     // Required, text/html or video/mp4
+	ThumbnailURL        string              `json:"thumbnail_url"` // Required
+	Title               string              `json:"title"`         // Required
+	Description         string              `json:"description,omitempty"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+func NewVideoResult(id, videoURL, mimeType, thumbnailURL, title string) *InlineQueryResultVideo {
+	return &InlineQueryResultVideo{
+		BaseInlineQueryResult: BaseInlineQueryResult{Type: "video", ID: id},
+		VideoURL:              videoURL,
+		MimeType:              mimeType,
+		ThumbnailURL:          thumbnailURL,
+		Title:                 title,
+	}
+}
+
+type InlineQueryResultDocument struct {
+	BaseInlineQueryResult
+	Title               string              `json:"title"`        // Required
+	DocumentURL         string              `json:"document_url"` // Required
+	MimeType            string              `json:"mime_type"`    // Required, application/pdf or application/zip
+	Description         string              `json:"description,omitempty"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	ThumbnailURL        string              `json:"thumbnail_url,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+func NewDocumentResult(id, title, documentURL, mimeType string) *InlineQueryResultDocument {
+	return &InlineQueryResultDocument{
+		BaseInlineQueryResult: BaseInlineQueryResult{Type: "document", ID: id},
+		Title:                 title,
+		DocumentURL:           documentURL,
+		MimeType:              mimeType,
+	}
+}
+
+// InlineQueryResultCached is a file already stored on Telegram's servers,
+// sent by its file_id. Create one with the NewCached*Result constructors.
+type InlineQueryResultCached struct {
+	BaseInlineQueryResult
+	PhotoFileID         string              `json:"photo_file_id,omitempty"`
+	GifFileID           string              `json:"gif_file_id,omitempty"`
+	VideoFileID         string              `json:"video_file_id,omitempty"`
+	DocumentFileID      string              `json:"document_file_id,omitempty"`
+	StickerFileID       string              `json:"sticker_file_id,omitempty"`
+	AudioFileID         string              `json:"audio_file_id,omitempty"`
+	VoiceFileID         string              `json:"voice_file_id,omitempty"`
+	Title               string              `json:"title,omitempty"` // Required for video, document and voice
+	Description         string              `json:"description,omitempty"`
+	Caption             string              `json:"caption,omitempty"`
+	ParseMode           string              `json:"parse_mode,omitempty"`
+	InputMessageContent InputMessageContent `json:"input_message_content,omitempty"`
+}
+
+func newCachedResult(resultType, id string) *InlineQueryResultCached {
+	return &InlineQueryResultCached{BaseInlineQueryResult: BaseInlineQueryResult{Type: resultType, ID: id}}
+}
+
+func NewCachedPhotoResult(id, fileID string) *InlineQueryResultCached {
+	r := newCachedResult("photo", id)
+	r.PhotoFileID = fileID
+	return r
+}
+
+func NewCachedGifResult(id, fileID string) *InlineQueryResultCached {
+	r := newCachedResult("gif", id)
+	r.GifFileID = fileID
+	return r
+}
+
+func NewCachedVideoResult(id, fileID, title string) *InlineQueryResultCached {
+	r := newCachedResult("video", id)
+	r.VideoFileID = fileID
+	r.Title = title
+	return r
+}
+
+func NewCachedDocumentResult(id, fileID, title string) *InlineQueryResultCached {
+	r := newCachedResult("document", id)
+	r.DocumentFileID = fileID
+	r.Title = title
+	return r
+}
+
+func NewCachedStickerResult(id, fileID string) *InlineQueryResultCached {
+	r := newCachedResult("sticker", id)
+	r.StickerFileID = fileID
+	return r
+}
+
+func NewCachedAudioResult(id, fileID string) *InlineQueryResultCached {
+	r := newCachedResult("audio", id)
+	r.AudioFileID = fileID
+	return r
+}
+
+func NewCachedVoiceResult(id, fileID, title string) *InlineQueryResultCached {
+	r := newCachedResult("voice", id)
+	r.VoiceFileID = fileID
+	r.Title = title
+	return r
+}
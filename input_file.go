@@ -0,0 +1,130 @@
+package tgx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// InputFile is a file to send. It either references a file Telegram can fetch
+// by itself (a file_id or an HTTP URL) or carries content to upload from a
+// local path, a byte slice or an io.Reader. Use the FileFrom* constructors.
+type InputFile struct {
+	FileID string // file_id of a file already on Telegram's servers
+	URL    string // HTTP URL for Telegram to download the file from
+	Path   string // Local file to upload
+	Data   []byte // In-memory content to upload
+	Reader io.Reader
+
+	Name     string // File name reported to Telegram; defaults to the base of Path
+	MimeType string // Content type of the upload; defaults to application/octet-stream
+
+	attach string // multipart field name when referenced as attach://<name>
+}
+
+// FileFromID sends a file already stored on Telegram's servers.
+func FileFromID(fileID string) *InputFile {
+	return &InputFile{FileID: fileID}
+}
+
+// FileFromURL lets Telegram download the file from url.
+func FileFromURL(url string) *InputFile {
+	return &InputFile{URL: url}
+}
+
+// FileFromPath uploads the file at path.
+func FileFromPath(path string) *InputFile {
+	return &InputFile{Path: path}
+}
+
+// FileFromBytes uploads data under the given file name.
+func FileFromBytes(name string, data []byte) *InputFile {
+	return &InputFile{Name: name, Data: data}
+}
+
+// FileFromReader uploads the content of r under the given file name.
+func FileFromReader(name string, r io.Reader) *InputFile {
+	return &InputFile{Name: name, Reader: r}
+}
+
+// isUpload reports whether the file content has to be sent with the request.
+func (f *InputFile) isUpload() bool {
+	return f.Path != "" || f.Data != nil || f.Reader != nil
+}
+
+// reference returns the string Telegram expects in place of the file.
+func (f *InputFile) reference() string {
+	switch {
+	case f.attach != "":
+		return "attach://" + f.attach
+	case f.FileID != "":
+		return f.FileID
+	default:
+		return f.URL
+	}
+}
+
+func (f *InputFile) fileName() string {
+	switch {
+	case f.Name != "":
+		return f.Name
+	case f.Path != "":
+		return filepath.Base(f.Path)
+	}
+	if named, ok := f.Reader.(interface{ Name() string }); ok {
+		return filepath.Base(named.Name())
+	}
+	return "file"
+}
+
+func (f *InputFile) mimeType() string {
+	if f.MimeType != "" {
+		return f.MimeType
+	}
+	return "application/octet-stream"
+}
+
+// String describes the file without its content, so that logging an
+// InputFile doesn't dump the bytes of an upload.
+func (f *InputFile) String() string {
+	switch {
+	case f.FileID != "":
+		return "file_id " + f.FileID
+	case f.URL != "":
+		return "url " + f.URL
+	case f.Data != nil:
+		return fmt.Sprintf("upload %s (%d bytes)", f.fileName(), len(f.Data))
+	case f.isUpload():
+		return "upload " + f.fileName()
+	}
+	return "empty file"
+}
+
+// open returns the content to upload. Readers supplied by the caller are not closed.
+func (f *InputFile) open() (io.ReadCloser, error) {
+	switch {
+	case f.Path != "":
+		file, err := os.Open(f.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file: %w", err)
+		}
+		return file, nil
+	case f.Data != nil:
+		return io.NopCloser(bytes.NewReader(f.Data)), nil
+	case f.Reader != nil:
+		return io.NopCloser(f.Reader), nil
+	}
+	return nil, fmt.Errorf("input file has no content to upload")
+}
+
+// MarshalJSON encodes the file the way it is referenced inside JSON
+// parameters such as the media array of sendMediaGroup.
+func (f InputFile) MarshalJSON() ([]byte, error) {
+	if f.isUpload() && f.attach == "" {
+		return nil, fmt.Errorf("file %q must be uploaded as a request parameter", f.fileName())
+	}
+	return json.Marshal(f.reference())
+}
@@ -1,14 +1,59 @@
 package tgx
 
 import (
+	"strings"
+
 	"github.com/harshyadavone/tgx/models"
 )
 
-func (b *Bot) OnMessage(messageType string, handler Handler) {
-	if b.messageHandlers == nil {
-		b.messageHandlers = make(map[string]Handler)
+// messageRoutes holds the command and message type handlers of one kind of
+// message update: new messages, edited messages, channel posts or edited
+// channel posts.
+type messageRoutes struct {
+	commands       map[string]Handler // keyed by lower case command
+	types          map[string]Handler
+	unknownCommand Handler
+	texts          []textRoute // tried in order before types["Text"]
+}
+
+func newMessageRoutes() messageRoutes {
+	return messageRoutes{
+		commands: make(map[string]Handler),
+		types:    make(map[string]Handler),
 	}
-	b.messageHandlers[messageType] = handler
+}
+
+// on registers handler for a message type such as "Text" or "Photo", or for
+// a command when key starts with a slash, e.g. "/start".
+func (r *messageRoutes) on(key string, handler Handler) {
+	if command, ok := strings.CutPrefix(key, "/"); ok {
+		r.commands[strings.ToLower(command)] = handler
+		return
+	}
+	r.types[key] = handler
+}
+
+// OnMessage handles new messages of the given type, such as "Text" or
+// "Photo". Commands are routed by prefixing them with a slash: "/start".
+func (b *Bot) OnMessage(messageType string, handler Handler) {
+	b.messages.on(messageType, withMiddleware(b, handler))
+}
+
+// OnEditedMessage handles edits of messages of the given type. Edits of
+// commands are routed by prefixing the command with a slash: "/start".
+func (b *Bot) OnEditedMessage(messageType string, handler Handler) {
+	b.editedMessages.on(messageType, withMiddleware(b, handler))
+}
+
+// OnChannelPost handles new posts in channels the bot is a member of, routed
+// by message type or, with a leading slash, by command.
+func (b *Bot) OnChannelPost(messageType string, handler Handler) {
+	b.channelPosts.on(messageType, withMiddleware(b, handler))
+}
+
+// OnEditedChannelPost handles edits of channel posts, routed like OnChannelPost.
+func (b *Bot) OnEditedChannelPost(messageType string, handler Handler) {
+	b.editedChannelPosts.on(messageType, withMiddleware(b, handler))
 }
 
 func (ctx *Context) Reply(text string) error {
@@ -1 +1,52 @@
 package tgx
+
+// HandlerFunc is a handler of any kind of update, as seen by middleware.
+type HandlerFunc func(ctx UpdateContext) error
+
+// Middleware wraps the next handler in the chain. It can act before and
+// after calling next, or return without calling it to stop the update from
+// reaching the handler:
+//
+//	func adminsOnly(next tgx.HandlerFunc) tgx.HandlerFunc {
+//		return func(ctx tgx.UpdateContext) error {
+//			if id, ok := tgx.ByUser(ctx.Update()); !ok || !admins[id] {
+//				return nil
+//			}
+//			return next(ctx)
+//		}
+//	}
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Use adds middleware that runs around every handler: commands, message
+// types, callbacks, inline queries and chat member updates. Middleware runs
+// in the order it was added, the first one being the outermost, and inside
+// the middleware of a Group. It only runs for updates that have a handler.
+func (b *Bot) Use(mw ...Middleware) {
+	b.middleware = append(b.middleware, mw...)
+}
+
+// chain wraps final in mw, the first middleware being the outermost.
+func chain(mw []Middleware, final HandlerFunc) HandlerFunc {
+	h := final
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+	return h
+}
+
+// run calls final for ctx through the bot's middleware.
+func (b *Bot) run(ctx UpdateContext, final HandlerFunc) error {
+	return chain(b.middleware, final)(ctx)
+}
+
+// withMiddleware wraps a handler being registered in the bot's middleware.
+// The middleware is looked up on every call, so Use also applies to the
+// handlers registered before it.
+func withMiddleware[C UpdateContext](b *Bot, handler func(C) error) func(C) error {
+	if handler == nil {
+		return nil
+	}
+	return func(ctx C) error {
+		return b.run(ctx, func(UpdateContext) error { return handler(ctx) })
+	}
+}
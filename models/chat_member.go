@@ -0,0 +1,233 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Chat member statuses as sent by Telegram.
+const (
+	MemberStatusOwner         = "creator"
+	MemberStatusAdministrator = "administrator"
+	MemberStatusMember        = "member"
+	MemberStatusRestricted    = "restricted"
+	MemberStatusLeft          = "left"
+	MemberStatusBanned        = "kicked"
+)
+
+// ChatMember is one of ChatMemberOwner, ChatMemberAdministrator,
+// ChatMemberMember, ChatMemberRestricted, ChatMemberLeft or ChatMemberBanned,
+// or ChatMemberUnknown for a status Telegram added after this package was
+// written. Use a type switch to get at the fields of a particular status.
+type ChatMember interface {
+	GetStatus() string
+	GetUser() User
+}
+
+type ChatMemberOwner struct {
+	Status      string `json:"status"`
+	User        User   `json:"user"`
+	IsAnonymous bool   `json:"is_anonymous"`
+	CustomTitle string `json:"custom_title,omitempty"`
+}
+
+type ChatMemberAdministrator struct {
+	Status              string `json:"status"`
+	User                User   `json:"user"`
+	CanBeEdited         bool   `json:"can_be_edited"`
+	IsAnonymous         bool   `json:"is_anonymous"`
+	CanManageChat       bool   `json:"can_manage_chat"`
+	CanDeleteMessages   bool   `json:"can_delete_messages"`
+	CanManageVideoChats bool   `json:"can_manage_video_chats"`
+	CanRestrictMembers  bool   `json:"can_restrict_members"`
+	CanPromoteMembers   bool   `json:"can_promote_members"`
+	CanChangeInfo       bool   `json:"can_change_info"`
+	CanInviteUsers      bool   `json:"can_invite_users"`
+	CanPostStories      bool   `json:"can_post_stories"`
+	CanEditStories      bool   `json:"can_edit_stories"`
+	CanDeleteStories    bool   `json:"can_delete_stories"`
+	CanPostMessages     bool   `json:"can_post_messages,omitempty"` // Channels only
+	CanEditMessages     bool   `json:"can_edit_messages,omitempty"` // Channels only
+	CanPinMessages      bool   `json:"can_pin_messages,omitempty"`
+	CanManageTopics     bool   `json:"can_manage_topics,omitempty"`
+	CustomTitle         string `json:"custom_title,omitempty"`
+}
+
+type ChatMemberMember struct {
+	Status    string `json:"status"`
+	User      User   `json:"user"`
+	UntilDate int64  `json:"until_date,omitempty"` // When the subscription expires
+}
+
+type ChatMemberRestricted struct {
+	Status                string `json:"status"`
+	User                  User   `json:"user"`
+	IsMember              bool   `json:"is_member"`
+	CanSendMessages       bool   `json:"can_send_messages"`
+	CanSendAudios         bool   `json:"can_send_audios"`
+	CanSendDocuments      bool   `json:"can_send_documents"`
+	CanSendPhotos         bool   `json:"can_send_photos"`
+	CanSendVideos         bool   `json:"can_send_videos"`
+	CanSendVideoNotes     bool   `json:"can_send_video_notes"`
+	CanSendVoiceNotes     bool   `json:"can_send_voice_notes"`
+	CanSendPolls          bool   `json:"can_send_polls"`
+	CanSendOtherMessages  bool   `json:"can_send_other_messages"`
+	CanAddWebPagePreviews bool   `json:"can_add_web_page_previews"`
+	CanChangeInfo         bool   `json:"can_change_info"`
+	CanInviteUsers        bool   `json:"can_invite_users"`
+	CanPinMessages        bool   `json:"can_pin_messages"`
+	CanManageTopics       bool   `json:"can_manage_topics"`
+	UntilDate             int64  `json:"until_date"` // 0 means forever
+}
+
+type ChatMemberLeft struct {
+	Status string `json:"status"`
+	User   User   `json:"user"`
+}
+
+type ChatMemberBanned struct {
+	Status    string `json:"status"`
+	User      User   `json:"user"`
+	UntilDate int64  `json:"until_date"` // 0 means forever
+}
+
+// ChatMemberUnknown keeps the status and user of a status this package
+// doesn't know, so that updates carrying it still decode.
+type ChatMemberUnknown struct {
+	Status string `json:"status"`
+	User   User   `json:"user"`
+}
+
+func (m *ChatMemberOwner) GetStatus() string         { return MemberStatusOwner }
+func (m *ChatMemberAdministrator) GetStatus() string { return MemberStatusAdministrator }
+func (m *ChatMemberMember) GetStatus() string        { return MemberStatusMember }
+func (m *ChatMemberRestricted) GetStatus() string    { return MemberStatusRestricted }
+func (m *ChatMemberLeft) GetStatus() string          { return MemberStatusLeft }
+func (m *ChatMemberBanned) GetStatus() string        { return MemberStatusBanned }
+func (m *ChatMemberUnknown) GetStatus() string       { return m.Status }
+
+func (m *ChatMemberOwner) GetUser() User         { return m.User }
+func (m *ChatMemberAdministrator) GetUser() User { return m.User }
+func (m *ChatMemberMember) GetUser() User        { return m.User }
+func (m *ChatMemberRestricted) GetUser() User    { return m.User }
+func (m *ChatMemberLeft) GetUser() User          { return m.User }
+func (m *ChatMemberBanned) GetUser() User        { return m.User }
+func (m *ChatMemberUnknown) GetUser() User       { return m.User }
+
+// UnmarshalChatMember decodes a ChatMember into the type matching its status.
+func UnmarshalChatMember(data []byte) (ChatMember, error) {
+	var probe struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+
+	var member ChatMember
+	switch probe.Status {
+	case MemberStatusOwner:
+		member = &ChatMemberOwner{}
+	case MemberStatusAdministrator:
+		member = &ChatMemberAdministrator{}
+	case MemberStatusMember:
+		member = &ChatMemberMember{}
+	case MemberStatusRestricted:
+		member = &ChatMemberRestricted{}
+	case MemberStatusLeft:
+		member = &ChatMemberLeft{}
+	case MemberStatusBanned:
+		member = &ChatMemberBanned{}
+	default:
+		member = &ChatMemberUnknown{}
+	}
+
+	if err := json.Unmarshal(data, member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// IsChatMember reports whether m is currently in the chat.
+func IsChatMember(m ChatMember) bool {
+	switch m := m.(type) {
+	case *ChatMemberOwner, *ChatMemberAdministrator, *ChatMemberMember:
+		return true
+	case *ChatMemberRestricted:
+		return m.IsMember
+	}
+	return false
+}
+
+// ChatMemberUpdated describes a change in the status of a chat member.
+type ChatMemberUpdated struct {
+	Chat                    Chat            `json:"chat"`
+	From                    User            `json:"from"` // Who made the change
+	Date                    int64           `json:"date"`
+	OldChatMember           ChatMember      `json:"old_chat_member"`
+	NewChatMember           ChatMember      `json:"new_chat_member"`
+	InviteLink              *ChatInviteLink `json:"invite_link,omitempty"`
+	ViaJoinRequest          bool            `json:"via_join_request,omitempty"`
+	ViaChatFolderInviteLink bool            `json:"via_chat_folder_invite_link,omitempty"`
+}
+
+type ChatInviteLink struct {
+	InviteLink         string `json:"invite_link"`
+	Creator            User   `json:"creator"`
+	CreatesJoinRequest bool   `json:"creates_join_request"`
+	IsPrimary          bool   `json:"is_primary"`
+	IsRevoked          bool   `json:"is_revoked"`
+	Name               string `json:"name,omitempty"`
+}
+
+func (u *ChatMemberUpdated) UnmarshalJSON(data []byte) error {
+	type plain ChatMemberUpdated
+	var raw struct {
+		plain
+		OldChatMember json.RawMessage `json:"old_chat_member"`
+		NewChatMember json.RawMessage `json:"new_chat_member"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	oldMember, err := UnmarshalChatMember(raw.OldChatMember)
+	if err != nil {
+		return fmt.Errorf("old_chat_member: %w", err)
+	}
+	newMember, err := UnmarshalChatMember(raw.NewChatMember)
+	if err != nil {
+		return fmt.Errorf("new_chat_member: %w", err)
+	}
+
+	*u = ChatMemberUpdated(raw.plain)
+	u.OldChatMember, u.NewChatMember = oldMember, newMember
+	return nil
+}
+
+// IsJoin reports whether the user became a member of the chat.
+func (u *ChatMemberUpdated) IsJoin() bool {
+	return !IsChatMember(u.OldChatMember) && IsChatMember(u.NewChatMember)
+}
+
+// IsLeave reports whether the user left the chat or was removed from it.
+func (u *ChatMemberUpdated) IsLeave() bool {
+	return IsChatMember(u.OldChatMember) && !IsChatMember(u.NewChatMember)
+}
+
+// IsPromotion reports whether the user became an administrator.
+func (u *ChatMemberUpdated) IsPromotion() bool {
+	return !isAdmin(u.OldChatMember) && isAdmin(u.NewChatMember)
+}
+
+// IsDemotion reports whether the user stopped being an administrator.
+func (u *ChatMemberUpdated) IsDemotion() bool {
+	return isAdmin(u.OldChatMember) && !isAdmin(u.NewChatMember)
+}
+
+func isAdmin(m ChatMember) bool {
+	switch m.(type) {
+	case *ChatMemberOwner, *ChatMemberAdministrator:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChatMemberUpdatedUnknownStatus(t *testing.T) {
+	data := []byte(`{
+		"chat": {"id": -100, "type": "supergroup"},
+		"from": {"id": 1, "first_name": "Admin"},
+		"date": 1700000000,
+		"old_chat_member": {"status": "left", "user": {"id": 2, "first_name": "Ann"}},
+		"new_chat_member": {"status": "lurker", "user": {"id": 2, "first_name": "Ann"}, "since": 3}
+	}`)
+
+	var u ChatMemberUpdated
+	if err := json.Unmarshal(data, &u); err != nil {
+		t.Fatalf("decoding an unknown status: %v", err)
+	}
+	member, ok := u.NewChatMember.(*ChatMemberUnknown)
+	if !ok {
+		t.Fatalf("new member is %T, want *ChatMemberUnknown", u.NewChatMember)
+	}
+	if member.GetStatus() != "lurker" || member.GetUser().Id != 2 {
+		t.Errorf("new member = %+v, want status lurker of user 2", member)
+	}
+	if _, ok := u.OldChatMember.(*ChatMemberLeft); !ok {
+		t.Errorf("old member is %T, want *ChatMemberLeft", u.OldChatMember)
+	}
+	if IsChatMember(member) {
+		t.Error("IsChatMember reports an unknown status as a member")
+	}
+}
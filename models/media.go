@@ -80,3 +80,11 @@ type Voice struct {
 	MimeType     string `json:"mime_type"`
 	FileSize     int64  `json:"file_size"`
 }
+
+// File is a file ready to be downloaded, as returned by getFile.
+type File struct {
+	FileId       string `json:"file_id"`
+	FileUniqueId string `json:"file_unique_id"`
+	FileSize     int64  `json:"file_size"`
+	FilePath     string `json:"file_path"` // Absolute path on disk when using a local Bot API server
+}
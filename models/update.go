@@ -1,21 +1,35 @@
 package models
 
 type Update struct {
-	UpdateId      int            `json:"update_id"`
-	Message       *Message       `json:"message"`
-	CallbackQuery *CallbackQuery `json:"callback_query"`
-	EditedMessage *Message       `json:"edited_message"`
-	InlineQuery   *InlineQuery   `json:"inline_query"`
+	UpdateId           int                 `json:"update_id"`
+	Message            *Message            `json:"message"`
+	CallbackQuery      *CallbackQuery      `json:"callback_query"`
+	EditedMessage      *Message            `json:"edited_message"`
+	ChannelPost        *Message            `json:"channel_post,omitempty"`
+	EditedChannelPost  *Message            `json:"edited_channel_post,omitempty"`
+	InlineQuery        *InlineQuery        `json:"inline_query"`
+	ChosenInlineResult *ChosenInlineResult `json:"chosen_inline_result,omitempty"`
+	MyChatMember       *ChatMemberUpdated  `json:"my_chat_member,omitempty"` // The bot's own status changed
+	ChatMember         *ChatMemberUpdated  `json:"chat_member,omitempty"`    // Another member's status changed
 }
 
 type InlineQuery struct {
-	Id       int64  `json:"id"`
+	Id       string `json:"id"`
 	From     User   `json:"from"`
 	Query    string `json:"query"`
 	Offset   string `json:"offset"`
 	ChatType string `json:"chat_type"`
 }
 
+// ChosenInlineResult is an inline query result that was chosen by a user and
+// sent to their chat partner. Requires inline feedback to be enabled.
+type ChosenInlineResult struct {
+	ResultId        string `json:"result_id"`
+	From            User   `json:"from"`
+	InlineMessageId string `json:"inline_message_id,omitempty"` // Set if the message has an inline keyboard
+	Query           string `json:"query"`
+}
+
 type CallbackQuery struct {
 	ID      string   `json:"id"`
 	From    User     `json:"from"`
@@ -26,7 +40,9 @@ type CallbackQuery struct {
 type Message struct {
 	MessageId      int64                 `json:"message_id"`
 	From           User                  `json:"from"`
+	SenderChat     *Chat                 `json:"sender_chat,omitempty"` // Set instead of From for channel posts
 	Chat           Chat                  `json:"chat"`
+	EditDate       int64                 `json:"edit_date,omitempty"`
 	Text           string                `json:"text"`
 	ReplyToMessage *Message              `json:"reply_to_message"`
 	ReplyMarkup    *InlineKeyboardMarkup `json:"reply_markup"`
@@ -40,3 +56,8 @@ type Message struct {
 	Voice          *Voice                `json:"voice"`
 	Caption        string                `json:"caption"`
 }
+
+// MessageId is returned by copyMessage, copyMessages and forwardMessages.
+type MessageId struct {
+	MessageId int64 `json:"message_id"`
+}
@@ -0,0 +1,129 @@
+package tgx
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAPIURL is the public Telegram Bot API server.
+	DefaultAPIURL = "https://api.telegram.org"
+
+	// DefaultTimeout bounds a regular JSON API call.
+	DefaultTimeout = 30 * time.Second
+	// DefaultUploadTimeout bounds a multipart file upload.
+	DefaultUploadTimeout = 5 * time.Minute
+)
+
+// Option configures a Bot created with NewBot.
+type Option func(*Bot)
+
+// WithAPIURL points every API call at a different Bot API server, such as a
+// self-hosted telegram-bot-api instance or an httptest.Server.
+// Unless WithFileURL is also given, file downloads use "<url>/file".
+func WithAPIURL(url string) Option {
+	return func(b *Bot) {
+		b.apiURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithFileURL overrides the base URL used to download files returned by getFile.
+func WithFileURL(url string) Option {
+	return func(b *Bot) {
+		b.fileBaseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithHTTPClient makes the bot send every request through client.
+// Timeouts configured on the bot are applied per request on top of
+// client.Timeout, so leave client.Timeout at zero to let them take effect.
+func WithHTTPClient(client *http.Client) Option {
+	return func(b *Bot) {
+		b.client = client
+	}
+}
+
+// WithTransport sets the RoundTripper of the bot's own http.Client.
+// It is ignored when WithHTTPClient is used.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(b *Bot) {
+		b.transport = transport
+	}
+}
+
+// WithProxy routes the bot's default transport through proxy,
+// e.g. http.ProxyURL(u) or http.ProxyFromEnvironment.
+// It is ignored when WithHTTPClient or WithTransport is used.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(b *Bot) {
+		b.proxy = proxy
+	}
+}
+
+// WithTimeout sets the timeout of regular JSON API calls. Zero disables it.
+func WithTimeout(timeout time.Duration) Option {
+	return func(b *Bot) {
+		b.timeout = timeout
+	}
+}
+
+// WithUploadTimeout sets the timeout of multipart file uploads.
+func WithUploadTimeout(timeout time.Duration) Option {
+	return func(b *Bot) {
+		b.uploadTimeout = timeout
+	}
+}
+
+// WithMethodTimeout overrides the timeout of a single Bot API method,
+// e.g. WithMethodTimeout("sendVideo", 10*time.Minute).
+// It takes precedence over WithTimeout and WithUploadTimeout.
+func WithMethodTimeout(method string, timeout time.Duration) Option {
+	return func(b *Bot) {
+		if b.methodTimeouts == nil {
+			b.methodTimeouts = make(map[string]time.Duration)
+		}
+		b.methodTimeouts[method] = timeout
+	}
+}
+
+// WithHandlerTimeout sets a deadline on the context handed to each update
+// handler. Zero, the default, leaves handlers without a deadline.
+func WithHandlerTimeout(timeout time.Duration) Option {
+	return func(b *Bot) {
+		b.handlerTimeout = timeout
+	}
+}
+
+// WithSecretToken sets the secret_token registered by SetWebhook. HandleWebhook
+// then rejects requests whose X-Telegram-Bot-Api-Secret-Token header doesn't match.
+func WithSecretToken(token string) Option {
+	return func(b *Bot) {
+		b.secretToken = token
+	}
+}
+
+// requestTimeout returns the timeout to apply to a call of method.
+func (b *Bot) requestTimeout(method string, upload bool) time.Duration {
+	if timeout, ok := b.methodTimeouts[method]; ok {
+		return timeout
+	}
+	if upload {
+		return b.uploadTimeout
+	}
+	return b.timeout
+}
+
+// newHTTPClient builds the bot's own client when none was supplied.
+func (b *Bot) newHTTPClient() *http.Client {
+	transport := b.transport
+	if transport == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		if b.proxy != nil {
+			t.Proxy = b.proxy
+		}
+		transport = t
+	}
+	return &http.Client{Transport: transport}
+}
@@ -55,6 +55,10 @@ func (pb *ParamBuilder) Add(key string, value interface{}) *ParamBuilder {
 		if v != nil {
 			pb.params[key] = *v
 		}
+	case *InputFile:
+		if v != nil {
+			pb.params[key] = v
+		}
 	default:
 		// pb.params[key] = value
 		log.Printf("Unsupported type for key: %s, skipping", key)
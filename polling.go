@@ -0,0 +1,193 @@
+package tgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/harshyadavone/tgx/models"
+)
+
+// PollingOptions configures StartPolling.
+type PollingOptions struct {
+	Timeout        time.Duration // Long polling timeout, 30s by default
+	Limit          int           // Updates fetched per request, 1-100
+	AllowedUpdates []string      // Update types to receive; empty keeps the current setting
+	DeleteWebhook  bool          // Remove an active webhook instead of refusing to start
+	MaxBackoff     time.Duration // Longest wait between failed getUpdates calls, 30s by default
+}
+
+const (
+	defaultPollingTimeout    = 30 * time.Second
+	defaultPollingMaxBackoff = 30 * time.Second
+	minPollingBackoff        = time.Second
+)
+
+func (b *Bot) GetUpdates(req *GetUpdatesRequest) ([]models.Update, error) {
+	return b.GetUpdatesCtx(context.Background(), req)
+}
+
+func (b *Bot) GetUpdatesCtx(ctx context.Context, req *GetUpdatesRequest) ([]models.Update, error) {
+	params := map[string]interface{}{
+		"offset":  req.Offset,
+		"timeout": req.Timeout,
+	}
+	if req.Limit > 0 {
+		params["limit"] = req.Limit
+	}
+	if req.AllowedUpdates != nil {
+		params["allowed_updates"] = req.AllowedUpdates
+	}
+
+	ctx = context.WithValue(ctx, longPollKey{}, time.Duration(req.Timeout)*time.Second)
+	return decodeResult[[]models.Update](b.makeAPIRequestWithResult(ctx, "getUpdates", params))
+}
+
+// StartPolling receives updates with getUpdates and feeds them to the same
+// handlers as HandleWebhook. It blocks until ctx is cancelled or Shutdown is
+// called, confirms the updates it has dispatched and returns nil. Telegram
+// refuses getUpdates while a webhook is set, so StartPolling returns an error
+// in that case unless opts.DeleteWebhook is set.
+func (b *Bot) StartPolling(ctx context.Context, opts *PollingOptions) error {
+	b.pollers.add()
+	defer b.pollers.done()
+	if b.done.Err() != nil {
+		return &BotError{
+			Code:    http.StatusServiceUnavailable,
+			Message: "Can't start polling",
+			Err:     errShuttingDown,
+		}
+	}
+
+	// Shutdown stops polling just like cancelling ctx does.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(b.done, cancel)()
+
+	if opts == nil {
+		opts = &PollingOptions{}
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultPollingTimeout
+	}
+	maxBackoff := opts.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultPollingMaxBackoff
+	}
+
+	info, err := b.GetWebhookInfoCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check webhook: %w", err)
+	}
+	if info.URL != "" {
+		if !opts.DeleteWebhook {
+			return &BotError{
+				Code:    http.StatusConflict,
+				Message: "A webhook is set; delete it or set PollingOptions.DeleteWebhook",
+				Err:     fmt.Errorf("webhook is set to %s", info.URL),
+			}
+		}
+		b.logger.Info("Deleting webhook %s to start polling", info.URL)
+		if err := b.DeleteWebhookCtx(ctx); err != nil {
+			return fmt.Errorf("failed to delete webhook: %w", err)
+		}
+	}
+
+	b.logger.Info("Polling for updates")
+
+	req := &GetUpdatesRequest{
+		Limit:          opts.Limit,
+		Timeout:        int(timeout / time.Second),
+		AllowedUpdates: opts.AllowedUpdates,
+	}
+	b.poll(ctx, req, maxBackoff)
+	b.commitOffset(ctx, req)
+
+	b.logger.Info("Polling stopped")
+	return nil
+}
+
+// poll fetches and dispatches updates until ctx is done. req.Offset is only
+// advanced past updates that were handed to the dispatcher.
+func (b *Bot) poll(ctx context.Context, req *GetUpdatesRequest, maxBackoff time.Duration) {
+	b.ReplayUpdates()
+	backoff := time.Duration(0)
+
+	for {
+		updates, err := b.GetUpdatesCtx(ctx, req)
+		if err == nil {
+			err = b.dispatchUpdates(ctx, req, updates)
+		}
+		if ctx.Err() != nil || errors.Is(err, errShuttingDown) {
+			return
+		}
+		if err != nil {
+			backoff = min(max(2*backoff, minPollingBackoff), maxBackoff)
+			b.logger.Error("Failed to get updates, retrying in %v: %v", backoff, err)
+			if !sleepCtx(ctx, backoff) {
+				return
+			}
+			continue
+		}
+		backoff = 0
+	}
+}
+
+// dispatchUpdates hands a batch of updates to the dispatcher and advances
+// req.Offset past each one it accepted or dropped.
+func (b *Bot) dispatchUpdates(ctx context.Context, req *GetUpdatesRequest, updates []models.Update) error {
+	for i := range updates {
+		update := &updates[i]
+		// Telegram may resend updates that were already confirmed when
+		// a request is retried; the offset tells them apart.
+		if update.UpdateId < req.Offset {
+			continue
+		}
+		switch err := b.dispatcher.dispatch(ctx, update, b.pool.Policy == QueueReject); {
+		case errors.Is(err, errQueueFull):
+			b.logger.Warn("Dropped update %d: %v", update.UpdateId, err)
+		case err != nil:
+			return err
+		}
+		req.Offset = update.UpdateId + 1
+	}
+	return nil
+}
+
+// commitOffset confirms the updates dispatched since the last getUpdates
+// call, so they aren't delivered again after a restart.
+func (b *Bot) commitOffset(ctx context.Context, req *GetUpdatesRequest) {
+	if req.Offset == 0 {
+		return
+	}
+
+	commit := &GetUpdatesRequest{Offset: req.Offset, Limit: 1, AllowedUpdates: req.AllowedUpdates}
+	if _, err := b.GetUpdatesCtx(context.WithoutCancel(ctx), commit); err != nil {
+		b.logger.Error("Failed to confirm updates up to %d: %v", req.Offset, err)
+	}
+}
+
+// sleepCtx waits for d, returning false if ctx is cancelled first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+type longPollKey struct{}
+
+// longPollTimeout returns how long the server may hold a request made with
+// ctx before answering, on top of the usual request timeout.
+func longPollTimeout(ctx context.Context) time.Duration {
+	d, _ := ctx.Value(longPollKey{}).(time.Duration)
+	return d
+}
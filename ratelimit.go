@@ -0,0 +1,248 @@
+package tgx
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate allows Limit requests every Per.
+type Rate struct {
+	Limit int
+	Per   time.Duration
+}
+
+// RateLimits are the outgoing message budgets enforced by the bot.
+// A zero Rate disables that particular limit.
+type RateLimits struct {
+	Global        Rate // All messages sent by the bot
+	PrivateChat   Rate // Messages to a single private chat
+	Group         Rate // Messages to a single group, supergroup or channel
+	PaidBroadcast Rate // Messages sent with allow_paid_broadcast, instead of Global
+}
+
+// DefaultRateLimits returns the limits documented by Telegram.
+func DefaultRateLimits() *RateLimits {
+	return &RateLimits{
+		Global:        Rate{Limit: 30, Per: time.Second},
+		PrivateChat:   Rate{Limit: 1, Per: time.Second},
+		Group:         Rate{Limit: 20, Per: time.Minute},
+		PaidBroadcast: Rate{Limit: 1000, Per: time.Second},
+	}
+}
+
+// WithRateLimits replaces the default outgoing rate limits.
+// A nil value turns rate limiting off.
+func WithRateLimits(limits *RateLimits) Option {
+	return func(b *Bot) {
+		if limits == nil {
+			b.limiter = nil
+			return
+		}
+		b.limiter = newRateLimiter(*limits)
+	}
+}
+
+// bucket is a token bucket that hands out reservations, so callers queue up
+// in the order they arrived instead of failing when the bucket is empty.
+type bucket struct {
+	mu     sync.Mutex
+	rate   Rate
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate Rate) *bucket {
+	return &bucket{rate: rate, tokens: float64(rate.Limit), last: time.Now()}
+}
+
+func (b *bucket) interval() time.Duration {
+	return b.rate.Per / time.Duration(b.rate.Limit)
+}
+
+func (b *bucket) refill(now time.Time) {
+	b.tokens += float64(now.Sub(b.last)) / float64(b.interval())
+	if limit := float64(b.rate.Limit); b.tokens > limit {
+		b.tokens = limit
+	}
+	b.last = now
+}
+
+// reserve takes a token and returns how long the caller has to wait for it.
+func (b *bucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens * float64(b.interval()))
+}
+
+// cancel gives back a token whose wait was abandoned.
+func (b *bucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens++
+}
+
+// idle reports whether the bucket has fully refilled.
+func (b *bucket) idle() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+	return b.tokens >= float64(b.rate.Limit)
+}
+
+func (b *bucket) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	delay := b.reserve()
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+const limiterSweepInterval = time.Minute
+
+type rateLimiter struct {
+	limits RateLimits
+	global *bucket
+	paid   *bucket
+
+	mu        sync.Mutex
+	chats     map[string]*bucket
+	lastSweep time.Time
+}
+
+func newRateLimiter(limits RateLimits) *rateLimiter {
+	return &rateLimiter{
+		limits:    limits,
+		global:    bucketFor(limits.Global),
+		paid:      bucketFor(limits.PaidBroadcast),
+		chats:     make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func bucketFor(rate Rate) *bucket {
+	if rate.Limit <= 0 || rate.Per <= 0 {
+		return nil
+	}
+	return newBucket(rate)
+}
+
+// isRateLimited reports whether method sends a message to a chat.
+func isRateLimited(method string) bool {
+	if method == "sendChatAction" {
+		return false
+	}
+	return strings.HasPrefix(method, "send") ||
+		strings.HasPrefix(method, "copyMessage") ||
+		strings.HasPrefix(method, "forwardMessage")
+}
+
+// wait blocks until a call of method with params fits into every budget it counts against.
+func (l *rateLimiter) wait(ctx context.Context, method string, params map[string]interface{}) error {
+	if l == nil || !isRateLimited(method) {
+		return nil
+	}
+
+	chat := l.chat(params["chat_id"])
+	if err := chat.wait(ctx); err != nil {
+		return err
+	}
+
+	shared := l.global
+	if paid, _ := params["allow_paid_broadcast"].(bool); paid && l.paid != nil {
+		shared = l.paid
+	}
+	if err := shared.wait(ctx); err != nil {
+		// The message isn't sent, so it mustn't use up the chat's budget.
+		if chat != nil {
+			chat.cancel()
+		}
+		return err
+	}
+	return nil
+}
+
+// chat returns the per-chat bucket for chatID, or nil if it can't be classified.
+// Private chats have positive ids; groups, supergroups and channels have
+// negative ids or are addressed as @username.
+func (l *rateLimiter) chat(chatID interface{}) *bucket {
+	var key string
+	var group bool
+
+	switch id := chatID.(type) {
+	case int64:
+		key, group = strconv.FormatInt(id, 10), id < 0
+	case int:
+		key, group = strconv.Itoa(id), id < 0
+	case int32:
+		key, group = strconv.FormatInt(int64(id), 10), id < 0
+	case string:
+		if id == "" {
+			return nil
+		}
+		key, group = id, strings.HasPrefix(id, "@") || strings.HasPrefix(id, "-")
+	default:
+		if chatID == nil {
+			return nil
+		}
+		key = fmt.Sprint(chatID)
+	}
+
+	rate := l.limits.PrivateChat
+	if group {
+		rate = l.limits.Group
+	}
+	if rate.Limit <= 0 || rate.Per <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep()
+
+	b, ok := l.chats[key]
+	if !ok {
+		b = newBucket(rate)
+		l.chats[key] = b
+	}
+	return b
+}
+
+// sweep drops buckets of chats that have been quiet long enough to refill.
+// It must be called with l.mu held.
+func (l *rateLimiter) sweep() {
+	if time.Since(l.lastSweep) < limiterSweepInterval {
+		return
+	}
+	l.lastSweep = time.Now()
+
+	for key, b := range l.chats {
+		if b.idle() {
+			delete(l.chats, key)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package tgx
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBucketReservations(t *testing.T) {
+	b := newBucket(Rate{Limit: 2, Per: time.Second})
+
+	for i := 0; i < 2; i++ {
+		if d := b.reserve(); d != 0 {
+			t.Fatalf("reservation %d within the burst waits %v", i, d)
+		}
+	}
+	// Callers queue up: each reservation past the burst waits one interval longer.
+	first, second := b.reserve(), b.reserve()
+	if first <= 0 || first > 500*time.Millisecond {
+		t.Errorf("first reservation past the burst waits %v, want up to 500ms", first)
+	}
+	if second <= first {
+		t.Errorf("second reservation waits %v, not longer than %v", second, first)
+	}
+
+	// Cancelled reservations give their tokens back.
+	b.cancel()
+	b.cancel()
+	if d := b.reserve(); d > first {
+		t.Errorf("reservation after cancelling waits %v, want at most %v", d, first)
+	}
+}
+
+func TestBucketWaitCancelled(t *testing.T) {
+	b := newBucket(Rate{Limit: 1, Per: time.Hour})
+	if err := b.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.wait(ctx); err == nil {
+		t.Fatal("wait for an empty bucket succeeded")
+	}
+	b.mu.Lock()
+	tokens := b.tokens
+	b.mu.Unlock()
+	if tokens < -0.01 {
+		t.Errorf("tokens = %v after a cancelled wait, want the reservation returned", tokens)
+	}
+}
+
+func TestRateLimiterReturnsChatTokenWhenGlobalWaitFails(t *testing.T) {
+	l := newRateLimiter(RateLimits{
+		Global:      Rate{Limit: 1, Per: time.Hour},
+		PrivateChat: Rate{Limit: 2, Per: time.Hour},
+	})
+	params := map[string]interface{}{"chat_id": int64(42)}
+
+	// Use up the global budget with another chat.
+	if err := l.wait(context.Background(), "sendMessage", map[string]interface{}{"chat_id": int64(7)}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx, "sendMessage", params); err == nil {
+		t.Fatal("wait with an exhausted global budget succeeded")
+	}
+
+	chat := l.chat(params["chat_id"])
+	chat.mu.Lock()
+	tokens := chat.tokens
+	chat.mu.Unlock()
+	if tokens < 1.99 {
+		t.Errorf("chat bucket holds %v tokens after the global wait was cancelled, want 2", tokens)
+	}
+}
+
+func TestRateLimiterPaidBroadcast(t *testing.T) {
+	l := newRateLimiter(RateLimits{
+		Global:        Rate{Limit: 1, Per: time.Hour},
+		PaidBroadcast: Rate{Limit: 10, Per: time.Second},
+	})
+	paid := map[string]interface{}{"chat_id": int64(1), "allow_paid_broadcast": true}
+
+	// Paid messages don't count against the global budget, nor wait for it.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := 0; i < 5; i++ {
+		if err := l.wait(ctx, "sendPhoto", paid); err != nil {
+			t.Fatalf("paid message %d: %v", i, err)
+		}
+	}
+	if err := l.wait(ctx, "sendMessage", map[string]interface{}{"chat_id": int64(2)}); err != nil {
+		t.Fatalf("unpaid message after paid ones: %v", err)
+	}
+}
@@ -3,11 +3,12 @@ package tgx
 import "github.com/harshyadavone/tgx/models"
 
 type SendMessageRequest struct {
-	ChatId      int64       `json:"chat_id"`              // Required
-	Text        string      `json:"text"`                 // Required
-	ParseMode   string      `json:"parse_mode,omitempty"` // MarkdownV2 || HTML
-	ReplyMarkup ReplyMarkup `json:"reply_markup,omitempty"`
-	ReplyParams *ReplyParam `json:"reply_paramaters,omitempty"`
+	ChatId             int64       `json:"chat_id"`              // Required
+	Text               string      `json:"text"`                 // Required
+	ParseMode          string      `json:"parse_mode,omitempty"` // MarkdownV2 || HTML
+	ReplyMarkup        ReplyMarkup `json:"reply_markup,omitempty"`
+	ReplyParams        *ReplyParam `json:"reply_paramaters,omitempty"`
+	AllowPaidBroadCast bool        `json:"allow_paid_broadcast,omitempty"`
 }
 
 type ReplyParam struct {
@@ -70,71 +71,63 @@ type BaseMediaRequest struct {
 
 type SendPhotoRequest struct {
 	BaseMediaRequest
-	Photo                 string `json:"photo"` // Required: file_path or file_id
-	ShowCaptionAboveMedia bool   `json:"show_caption_above_media,omitempty"`
-	HasSpoiler            bool   `json:"has_spoiler,omitempty"`
+	Photo                 *InputFile `json:"photo"` // Required: file_id, URL or upload
+	ShowCaptionAboveMedia bool       `json:"show_caption_above_media,omitempty"`
+	HasSpoiler            bool       `json:"has_spoiler,omitempty"`
 }
 
 type SendAudioRequest struct {
 	BaseMediaRequest
-	Audio     string `json:"audio"`               // Required: file_path or file_id
-	Duration  int64  `json:"duration,omitempty"`  // Optional: duration in seconds
-	Performer string `json:"performer,omitempty"` // Optional
-	Title     string `json:"title,omitempty"`     // Optional
+	Audio     *InputFile `json:"audio"`               // Required: file_id, URL or upload
+	Duration  int64      `json:"duration,omitempty"`  // Optional: duration in seconds
+	Performer string     `json:"performer,omitempty"` // Optional
+	Title     string     `json:"title,omitempty"`     // Optional
 }
 
 type SendVideoRequest struct {
 	BaseMediaRequest
-	Video                 string `json:"video"`              // Required: file_path or file_id
-	Duration              int64  `json:"duration,omitempty"` // Optional: duration in seconds
-	Width                 int64  `json:"width,omitempty"`    // Optional
-	Height                int64  `json:"height,omitempty"`   // Optional
-	HasSpoiler            bool   `json:"has_spoiler,omitempty"`
-	ShowCaptionAboveMedia bool   `json:"show_caption_above_media,omitempty"`
-	SupportsStreaming     bool   `json:"supports_streaming,omitempty"` // Optional
+	Video                 *InputFile `json:"video"`              // Required: file_id, URL or upload
+	Duration              int64      `json:"duration,omitempty"` // Optional: duration in seconds
+	Width                 int64      `json:"width,omitempty"`    // Optional
+	Height                int64      `json:"height,omitempty"`   // Optional
+	HasSpoiler            bool       `json:"has_spoiler,omitempty"`
+	ShowCaptionAboveMedia bool       `json:"show_caption_above_media,omitempty"`
+	SupportsStreaming     bool       `json:"supports_streaming,omitempty"` // Optional
 }
 
 type SendDocumentRequest struct {
 	BaseMediaRequest
-	Document                    string `json:"document"`                       // Required: file_path or file_id
-	DisableContentTypeDetection bool   `json:"disable_content_type_detection"` // Optional
+	Document                    *InputFile `json:"document"`                       // Required: file_id, URL or upload
+	DisableContentTypeDetection bool       `json:"disable_content_type_detection"` // Optional
 }
 
 type SendAnimationRequest struct {
 	BaseMediaRequest
-	Animation             string `json:"animation"`          // Required: file_path or file_id
-	Duration              int64  `json:"duration,omitempty"` // Optional: duration in seconds
-	Width                 int64  `json:"width,omitempty"`    // Optional
-	Height                int64  `json:"height,omitempty"`   // Optional
-	ShowCaptionAboveMedia bool   `json:"show_caption_above_media,omitempty"`
-	HasSpoiler            bool   `json:"has_spoiler,omitempty"`
+	Animation             *InputFile `json:"animation"`          // Required: file_id, URL or upload
+	Duration              int64      `json:"duration,omitempty"` // Optional: duration in seconds
+	Width                 int64      `json:"width,omitempty"`    // Optional
+	Height                int64      `json:"height,omitempty"`   // Optional
+	ShowCaptionAboveMedia bool       `json:"show_caption_above_media,omitempty"`
+	HasSpoiler            bool       `json:"has_spoiler,omitempty"`
 }
 
 type SendVoiceRequest struct {
 	BaseMediaRequest
-	Voice    string `json:"voice"`              // Required: file_path or file_id
-	Duration int64  `json:"duration,omitempty"` // Optional: duration in seconds
+	Voice    *InputFile `json:"voice"`              // Required: file_id, URL or upload
+	Duration int64      `json:"duration,omitempty"` // Optional: duration in seconds
 }
 
 type SendVideoNoteRequest struct {
 	BaseMediaRequest
-	VideoNote string `json:"video_note"`         // Required: file_path or file_id
-	Duration  int64  `json:"duration,omitempty"` // Optional: duration in seconds
-	Length    int64  `json:"length,omitempty"`   // Optional
+	VideoNote *InputFile `json:"video_note"`         // Required: file_id, URL or upload
+	Duration  int64      `json:"duration,omitempty"` // Optional: duration in seconds
+	Length    int64      `json:"length,omitempty"`   // Optional
 }
 
 type SendStickerRequest struct {
 	BaseMediaRequest
-	Sticker string `json:"sticker"`
-	Emoji   string `json:"emoji,omitempty"` // only for uploaded stickers
-}
-
-// MediaFile represents a file to be sent in a media group
-type MediaFile struct {
-	ParamName string // e.g., "photo", "video"
-	FilePath  string
-	FileType  string // mime type
-	Media     string // this will be used in the media array json
+	Sticker *InputFile `json:"sticker"`
+	Emoji   string     `json:"emoji,omitempty"` // only for uploaded stickers
 }
 
 // SendMediaGroupRequest represents the structure for sending multiple media files
@@ -143,20 +136,21 @@ type SendMediaGroupRequest struct {
 	Media               []InputMedia `json:"media"`
 	DisableNotification bool         `json:"disable_notification,omitempty"`
 	ProtectContent      bool         `json:"protect_content,omitempty"`
+	AllowPaidBroadCast  bool         `json:"allow_paid_broadcast,omitempty"`
 	ReplyParams         *ReplyParam  `json:"reply_parameters,omitempty"`
 }
 
 // InputMedia represents a single media in the group
 type InputMedia struct {
-	Type              string `json:"type"`  // "photo", "video", etc.
-	Media             string `json:"media"` // file_id or "attach://<file_name>"
-	Caption           string `json:"caption,omitempty"`
-	ParseMode         string `json:"parse_mode,omitempty"`
-	HasSpoiler        bool   `json:"has_spoiler,omitempty"`
-	Duration          int    `json:"duration,omitempty"`           // For videos
-	Width             int    `json:"width,omitempty"`              // For videos
-	Height            int    `json:"height,omitempty"`             // For videos
-	SupportsStreaming bool   `json:"supports_streaming,omitempty"` // For videos
+	Type              string     `json:"type"`  // "photo", "video", etc.
+	Media             *InputFile `json:"media"` // file_id, URL or upload
+	Caption           string     `json:"caption,omitempty"`
+	ParseMode         string     `json:"parse_mode,omitempty"`
+	HasSpoiler        bool       `json:"has_spoiler,omitempty"`
+	Duration          int        `json:"duration,omitempty"`           // For videos
+	Width             int        `json:"width,omitempty"`              // For videos
+	Height            int        `json:"height,omitempty"`             // For videos
+	SupportsStreaming bool       `json:"supports_streaming,omitempty"` // For videos
 }
 
 type SendChatActionRequest struct {
@@ -246,3 +240,40 @@ type EditMessageOptions struct {
 	DisableWebPagePreview bool                         `json:"disable_web_page_preview,omitempty"`
 	ReplyMarkup           *models.InlineKeyboardMarkup `json:"reply_markup,omitempty"`
 }
+
+type GetUpdatesRequest struct {
+	Offset         int      `json:"offset,omitempty"`          // Identifier of the first update to be returned
+	Limit          int      `json:"limit,omitempty"`           // 1-100, defaults to 100
+	Timeout        int      `json:"timeout,omitempty"`         // Long polling timeout in seconds
+	AllowedUpdates []string `json:"allowed_updates,omitempty"` // e.g. "message", "callback_query"
+}
+
+type SetWebhookRequest struct {
+	URL                string     `json:"url"`                            // Required; defaults to the bot's webhook URL
+	Certificate        *InputFile `json:"certificate,omitempty"`          // Public key certificate for self-signed setups
+	IPAddress          string     `json:"ip_address,omitempty"`           // Fixed IP to send updates to instead of resolving URL
+	MaxConnections     int        `json:"max_connections,omitempty"`      // 1-100, defaults to 40
+	AllowedUpdates     []string   `json:"allowed_updates,omitempty"`      // e.g. "message", "callback_query"
+	DropPendingUpdates bool       `json:"drop_pending_updates,omitempty"` // Discard updates queued while no webhook was set
+}
+
+type AnswerInlineQueryRequest struct {
+	InlineQueryId string                    `json:"inline_query_id"` // Required
+	Results       []InlineQueryResult       `json:"results"`         // Required, at most 50
+	CacheTime     int                       `json:"cache_time,omitempty"`
+	IsPersonal    bool                      `json:"is_personal,omitempty"`
+	NextOffset    string                    `json:"next_offset,omitempty"` // Sent back as the offset of the next query; empty if there are no more results
+	Button        *InlineQueryResultsButton `json:"button,omitempty"`
+}
+
+// InlineQueryResultsButton is shown above the results. Exactly one of
+// WebApp and StartParameter must be set.
+type InlineQueryResultsButton struct {
+	Text           string      `json:"text"`                      // Required
+	WebApp         *WebAppInfo `json:"web_app,omitempty"`         // Web App launched when the button is pressed
+	StartParameter string      `json:"start_parameter,omitempty"` // Deep-linking parameter for /start
+}
+
+type WebAppInfo struct {
+	URL string `json:"url"` // Required, HTTPS
+}
@@ -0,0 +1,89 @@
+package tgx
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how failed API calls are retried.
+//
+// Flood-control errors (429) are retried after the retry_after period sent by
+// Telegram. Server errors (5xx) and network failures are retried with
+// exponential backoff and jitter. Any other error is returned immediately.
+type RetryPolicy struct {
+	MaxRetries    int           // Retries after the first attempt
+	MinBackoff    time.Duration // Backoff before the first retry of a 5xx or network error
+	MaxBackoff    time.Duration // Upper bound of the exponential backoff
+	MaxRetryAfter time.Duration // Give up when Telegram asks to wait longer than this; zero means no limit
+}
+
+// DefaultRetryPolicy returns the policy used by WithRetry(nil).
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: 3,
+		MinBackoff: 500 * time.Millisecond,
+		MaxBackoff: 30 * time.Second,
+	}
+}
+
+// WithRetry enables automatic retries. A nil policy uses DefaultRetryPolicy.
+func WithRetry(policy *RetryPolicy) Option {
+	return func(b *Bot) {
+		if policy == nil {
+			policy = DefaultRetryPolicy()
+		}
+		b.retryPolicy = policy
+	}
+}
+
+// backoff reports whether err is worth retrying after attempt failed attempts
+// and how long to wait first. A nil policy never retries.
+func (p *RetryPolicy) backoff(attempt int, err error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxRetries {
+		return 0, false
+	}
+
+	var botErr *BotError
+	if !errors.As(err, &botErr) {
+		return 0, false
+	}
+
+	var apiErr *APIError
+	if errors.As(botErr.Err, &apiErr) {
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests:
+			wait := time.Duration(apiErr.Parameters.RetryAfter) * time.Second
+			if p.MaxRetryAfter > 0 && wait > p.MaxRetryAfter {
+				return 0, false
+			}
+			return wait, true
+		case apiErr.Code >= 500:
+			return p.jitter(attempt), true
+		}
+		return 0, false
+	}
+
+	// No Telegram error: the request failed in transit or the server answered
+	// with something other than a Bot API response. Plain 500s are left alone,
+	// as that is the code used for requests that could not be built at all.
+	if botErr.Code > http.StatusInternalServerError {
+		return p.jitter(attempt), true
+	}
+	return 0, false
+}
+
+// jitter returns an exponential backoff for attempt with "equal jitter":
+// a random duration between half and all of the computed backoff.
+func (p *RetryPolicy) jitter(attempt int) time.Duration {
+	backoff := p.MinBackoff << attempt
+	if backoff <= 0 || (p.MaxBackoff > 0 && backoff > p.MaxBackoff) {
+		backoff = p.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	half := backoff / 2
+	return half + rand.N(half+1)
+}
@@ -0,0 +1,67 @@
+package tgx
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func apiError(code, retryAfter int) error {
+	return &BotError{
+		Code: code,
+		Err:  &APIError{Code: code, Parameters: ResponseParameters{RetryAfter: retryAfter}},
+	}
+}
+
+func TestRetryBackoffClassification(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxRetries:    3,
+		MinBackoff:    100 * time.Millisecond,
+		MaxBackoff:    time.Second,
+		MaxRetryAfter: 10 * time.Second,
+	}
+
+	tests := []struct {
+		name    string
+		attempt int
+		err     error
+		retry   bool
+		min     time.Duration
+		max     time.Duration
+	}{
+		{name: "flood control", err: apiError(429, 3), retry: true, min: 3 * time.Second, max: 3 * time.Second},
+		{name: "flood control too long", err: apiError(429, 60)},
+		{name: "server error", err: apiError(502, 0), retry: true, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "server error backs off", attempt: 2, err: apiError(500, 0), retry: true, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{name: "bad request", err: apiError(400, 0)},
+		{name: "blocked", err: apiError(403, 0)},
+		{name: "network failure", err: &BotError{Code: 503, Err: errors.New("connection reset")}, retry: true, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "request not built", err: &BotError{Code: 500, Err: errors.New("bad params")}},
+		{name: "not a BotError", err: errors.New("boom")},
+		{name: "out of retries", attempt: 3, err: apiError(502, 0)},
+	}
+
+	for _, tt := range tests {
+		delay, retry := policy.backoff(tt.attempt, tt.err)
+		if retry != tt.retry {
+			t.Errorf("%s: retry = %v, want %v", tt.name, retry, tt.retry)
+			continue
+		}
+		if retry && (delay < tt.min || delay > tt.max) {
+			t.Errorf("%s: delay %v not in [%v, %v]", tt.name, delay, tt.min, tt.max)
+		}
+	}
+
+	if _, retry := (*RetryPolicy)(nil).backoff(0, apiError(502, 0)); retry {
+		t.Error("a nil policy retried")
+	}
+}
+
+func TestRetryJitterIsCapped(t *testing.T) {
+	policy := &RetryPolicy{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	for attempt := 0; attempt < 70; attempt++ {
+		if d := policy.jitter(attempt); d < 0 || d > policy.MaxBackoff {
+			t.Fatalf("jitter(%d) = %v, want within [0, %v]", attempt, d, policy.MaxBackoff)
+		}
+	}
+}
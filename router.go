@@ -0,0 +1,76 @@
+package tgx
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Router registers handlers that run extra middleware of their own, such as
+// an admin-only set of commands. Create one with Bot.Group.
+type Router struct {
+	bot        *Bot
+	parent     *Router
+	middleware []Middleware
+}
+
+// Group returns a router whose handlers run mw first, then the middleware
+// added with Bot.Use, and then the handler itself.
+func (b *Bot) Group(mw ...Middleware) *Router {
+	return &Router{bot: b, middleware: mw}
+}
+
+// Group returns a nested router. Its handlers run the middleware of r
+// first and then mw.
+func (r *Router) Group(mw ...Middleware) *Router {
+	return &Router{bot: r.bot, parent: r, middleware: mw}
+}
+
+// Use adds middleware to the group, including handlers registered before.
+func (r *Router) Use(mw ...Middleware) {
+	r.middleware = append(r.middleware, mw...)
+}
+
+func (r *Router) OnCommand(command string, handler Handler) {
+	r.bot.messages.commands[strings.ToLower(command)] = wrap(r, handler)
+}
+
+func (r *Router) OnMessage(messageType string, handler Handler) {
+	r.bot.messages.on(messageType, wrap(r, handler))
+}
+
+func (r *Router) OnText(re *regexp.Regexp, handler Handler) {
+	r.bot.messages.text(re, wrap(r, handler))
+}
+
+func (r *Router) OnHears(keyword string, handler Handler) {
+	r.bot.messages.text(hearsPattern(keyword), wrap(r, handler))
+}
+
+func (r *Router) OnCallback(data string, handler callbackHandler) {
+	r.bot.addCallback(data, wrap(r, handler))
+}
+
+func (r *Router) OnCallbackPrefix(prefix string, handler callbackHandler) {
+	r.bot.callbackRoutes = append(r.bot.callbackRoutes, callbackRoute{prefix: prefix, handler: wrap(r, handler)})
+}
+
+func (r *Router) OnCallbackRegex(re *regexp.Regexp, handler callbackHandler) {
+	r.bot.callbackRoutes = append(r.bot.callbackRoutes, callbackRoute{re: re, handler: wrap(r, handler)})
+}
+
+// wrap runs handler through the middleware of r and then the bot's.
+func wrap[C UpdateContext](r *Router, handler func(C) error) func(C) error {
+	inner := withMiddleware(r.bot, handler)
+	return func(ctx C) error {
+		return chain(r.stack(), func(UpdateContext) error { return inner(ctx) })(ctx)
+	}
+}
+
+// stack returns the middleware of r and its parents, outermost first.
+func (r *Router) stack() []Middleware {
+	if r.parent == nil {
+		return r.middleware
+	}
+	return slices.Concat(r.parent.stack(), r.middleware)
+}
@@ -0,0 +1,91 @@
+package tgx
+
+import (
+	"context"
+	"sync"
+)
+
+// Shutdown stops the bot gracefully. Webhook requests are answered with 503
+// from then on so Telegram delivers the updates again later, and StartPolling
+// returns after confirming the updates it has already handed to handlers.
+// Shutdown then waits for running handlers and for API calls still in flight,
+// such as messages held back by rate limiting, until ctx is done. The update
+// log, if any, is closed once the handlers are done.
+//
+// A bot can't be restarted after Shutdown.
+func (b *Bot) Shutdown(ctx context.Context) error {
+	b.logger.Info("Shutting down")
+	b.stop()
+
+	if err := b.pollers.wait(ctx); err != nil {
+		return err
+	}
+	if err := b.dispatcher.shutdown(ctx); err != nil {
+		b.logger.Warn("Gave up waiting for %d update handlers: %v", b.dispatcher.handlers.count(), err)
+		return err
+	}
+	if b.updateLog != nil {
+		if err := b.updateLog.Close(); err != nil {
+			b.logger.Error("Failed to close update log: %v", err)
+		}
+	}
+	if err := b.outbound.wait(ctx); err != nil {
+		b.logger.Warn("Gave up waiting for %d API calls: %v", b.outbound.count(), err)
+		return err
+	}
+
+	b.logger.Info("Shutdown complete")
+	return nil
+}
+
+// tracker counts operations in flight so they can be waited for.
+// Unlike sync.WaitGroup it may be waited on while operations are being added.
+type tracker struct {
+	mu   sync.Mutex
+	n    int
+	idle chan struct{}
+}
+
+func (t *tracker) add() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.n == 0 {
+		t.idle = make(chan struct{})
+	}
+	t.n++
+}
+
+func (t *tracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.n--
+	if t.n == 0 {
+		close(t.idle)
+	}
+}
+
+func (t *tracker) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.n
+}
+
+// wait blocks until nothing is in flight or ctx is done.
+func (t *tracker) wait(ctx context.Context) error {
+	t.mu.Lock()
+	if t.n == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	idle := t.idle
+	t.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,60 @@
+package tgx
+
+import (
+	"regexp"
+	"strings"
+)
+
+// textRoute routes text messages that match re.
+type textRoute struct {
+	re      *regexp.Regexp
+	handler Handler
+}
+
+// OnText handles text messages that match re. The text and capture groups
+// that matched are in ctx.Matches, and named groups are available through
+// ctx.Match. Routes registered with OnText and OnHears are tried in the order
+// they were registered; messages that match none of them go to the "Text"
+// handler registered with OnMessage.
+func (b *Bot) OnText(re *regexp.Regexp, handler Handler) {
+	b.messages.text(re, withMiddleware(b, handler))
+}
+
+// OnHears handles text messages that consist of keyword, ignoring case and
+// surrounding whitespace. It is tried in order with the OnText routes.
+func (b *Bot) OnHears(keyword string, handler Handler) {
+	b.OnText(hearsPattern(keyword), handler)
+}
+
+func hearsPattern(keyword string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^\s*` + regexp.QuoteMeta(strings.TrimSpace(keyword)) + `\s*$`)
+}
+
+func (r *messageRoutes) text(re *regexp.Regexp, handler Handler) {
+	r.texts = append(r.texts, textRoute{re: re, handler: handler})
+}
+
+// matchText returns the handler of the first text route matching ctx.Text
+// and stores what it matched in ctx, or nil if no route matches.
+func (r *messageRoutes) matchText(ctx *Context) Handler {
+	for _, route := range r.texts {
+		if match := route.re.FindStringSubmatch(ctx.Text); match != nil {
+			ctx.Matches = match
+			ctx.textRoute = route.re
+			return route.handler
+		}
+	}
+	return nil
+}
+
+// Match returns the text matched by the named capture group of the OnText
+// route that matched the message, or "" if there is none.
+func (ctx *Context) Match(name string) string {
+	if ctx.textRoute == nil || name == "" {
+		return ""
+	}
+	if i := ctx.textRoute.SubexpIndex(name); i >= 0 && i < len(ctx.Matches) {
+		return ctx.Matches[i]
+	}
+	return ""
+}
@@ -0,0 +1,416 @@
+package tgx
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/harshyadavone/tgx/models"
+)
+
+const (
+	// DefaultMaxAttempts is how many times an update is started before it is dead-lettered.
+	DefaultMaxAttempts = 3
+	// DefaultSegmentSize is the size at which the update log starts a new segment file.
+	DefaultSegmentSize = 16 << 20
+
+	segmentExt     = ".seg"
+	deadLetterFile = "dead-letter.jsonl"
+)
+
+// UpdateLogOptions configures OpenUpdateLog.
+type UpdateLogOptions struct {
+	MaxAttempts int   // Starts before an update goes to the dead-letter file
+	SegmentSize int64 // Size in bytes after which a new segment is started
+}
+
+// UpdateLog is a durable queue of incoming updates kept in a directory.
+//
+// Every update is appended to the log and synced to disk before Telegram is
+// told it arrived. The log records when its handler starts and when it
+// finishes, so the updates that were still pending when the process stopped
+// are handled again the next time the bot starts receiving updates. An
+// update that has been started MaxAttempts times without succeeding, because
+// its handler keeps returning an error, panicking or crashing the process, is
+// moved to dead-letter.jsonl. Errors that won't go away by trying again, like
+// a 403 from a user who blocked the bot, finish the update instead, and the
+// bot's error handler only sees the error of the last attempt.
+//
+// The log is an append-only sequence of segment files; a segment is deleted
+// once every update it holds, and every update before them, has finished.
+type UpdateLog struct {
+	dir         string
+	maxAttempts int
+	segmentSize int64
+
+	mu       sync.Mutex
+	segments []*segment
+	pending  map[int64]*logEntry
+	nextID   int64
+	closed   bool
+}
+
+type segment struct {
+	first   int64 // id of the first update appended to it
+	path    string
+	file    *os.File // only the last segment is open
+	size    int64
+	pending int // updates added here that haven't finished
+}
+
+type logEntry struct {
+	seg      *segment
+	update   *models.Update
+	attempts int
+}
+
+// logRecord is a line of a segment file.
+type logRecord struct {
+	Op     string         `json:"op"` // add, start or done
+	ID     int64          `json:"id"`
+	Update *models.Update `json:"update,omitempty"`
+}
+
+// deadLetter is a line of the dead-letter file.
+type deadLetter struct {
+	Update   *models.Update `json:"update"`
+	Attempts int            `json:"attempts"`
+	Time     time.Time      `json:"time"`
+}
+
+// OpenUpdateLog opens the update log in dir, creating the directory if needed,
+// and loads the updates that haven't finished yet. Pass it to WithUpdateLog.
+func OpenUpdateLog(dir string, opts *UpdateLogOptions) (*UpdateLog, error) {
+	l := &UpdateLog{
+		dir:         dir,
+		maxAttempts: DefaultMaxAttempts,
+		segmentSize: DefaultSegmentSize,
+		pending:     make(map[int64]*logEntry),
+		nextID:      1,
+	}
+	if opts != nil {
+		if opts.MaxAttempts > 0 {
+			l.maxAttempts = opts.MaxAttempts
+		}
+		if opts.SegmentSize > 0 {
+			l.segmentSize = opts.SegmentSize
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create update log directory: %w", err)
+	}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	if err := l.rotate(); err != nil {
+		return nil, err
+	}
+	if err := l.compact(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// WithUpdateLog persists incoming updates in l before acknowledging them.
+// Shutdown closes the log once all handlers have finished.
+func WithUpdateLog(l *UpdateLog) Option {
+	return func(b *Bot) {
+		b.updateLog = l
+	}
+}
+
+// ReplayUpdates hands the updates left unfinished by an earlier run to the
+// handlers, without waiting for them to be handled. StartPolling and
+// ListenWebhook call it before taking new updates. With HandleWebhook, call
+// it once the handlers are registered; otherwise the first webhook request
+// does. Only the first call has an effect.
+func (b *Bot) ReplayUpdates() {
+	b.replayOnce.Do(b.dispatcher.replay)
+}
+
+// load replays the segment files to find the updates that haven't finished.
+func (l *UpdateLog) load() error {
+	paths, err := filepath.Glob(filepath.Join(l.dir, "*"+segmentExt))
+	if err != nil {
+		return fmt.Errorf("failed to list update log segments: %w", err)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		first, err := strconv.ParseInt(strings.TrimSuffix(filepath.Base(path), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		seg := &segment{first: first, path: path}
+		if err := l.loadSegment(seg); err != nil {
+			return err
+		}
+		l.segments = append(l.segments, seg)
+	}
+	return nil
+}
+
+func (l *UpdateLog) loadSegment(seg *segment) error {
+	file, err := os.Open(seg.path)
+	if err != nil {
+		return fmt.Errorf("failed to open update log segment: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		seg.size += int64(len(line)) + 1
+
+		var rec logRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			// A torn write at the end of the log; the update was never acknowledged.
+			continue
+		}
+
+		switch rec.Op {
+		case "add":
+			if rec.Update == nil {
+				continue
+			}
+			l.pending[rec.ID] = &logEntry{seg: seg, update: rec.Update}
+			seg.pending++
+		case "start":
+			if e, ok := l.pending[rec.ID]; ok {
+				e.attempts++
+			}
+		case "done":
+			if e, ok := l.pending[rec.ID]; ok {
+				e.seg.pending--
+				delete(l.pending, rec.ID)
+			}
+		}
+		if rec.ID >= l.nextID {
+			l.nextID = rec.ID + 1
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read update log segment %s: %w", seg.path, err)
+	}
+	return nil
+}
+
+// rotate starts a new segment. It must be called with l.mu held.
+func (l *UpdateLog) rotate() error {
+	if n := len(l.segments); n > 0 && l.segments[n-1].file != nil {
+		if err := l.segments[n-1].file.Close(); err != nil {
+			return fmt.Errorf("failed to close update log segment: %w", err)
+		}
+		l.segments[n-1].file = nil
+	}
+
+	seg := &segment{
+		first: l.nextID,
+		path:  filepath.Join(l.dir, fmt.Sprintf("%020d%s", l.nextID, segmentExt)),
+	}
+	file, err := os.OpenFile(seg.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create update log segment: %w", err)
+	}
+	if info, err := file.Stat(); err == nil {
+		seg.size = info.Size()
+	}
+	seg.file = file
+
+	// Reopening the segment of an earlier run: drop its stale copy.
+	if n := len(l.segments); n > 0 && l.segments[n-1].path == seg.path {
+		seg.pending = l.segments[n-1].pending
+		l.segments = l.segments[:n-1]
+	}
+	l.segments = append(l.segments, seg)
+	return nil
+}
+
+// write appends rec to the current segment. It must be called with l.mu held.
+func (l *UpdateLog) write(rec logRecord, sync bool) error {
+	if l.closed {
+		return errors.New("update log is closed")
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode update log record: %w", err)
+	}
+	data = append(data, '\n')
+
+	seg := l.segments[len(l.segments)-1]
+	if seg.size > 0 && seg.size+int64(len(data)) > l.segmentSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+		seg = l.segments[len(l.segments)-1]
+	}
+
+	n, err := seg.file.Write(data)
+	seg.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write update log: %w", err)
+	}
+	if sync {
+		if err := seg.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync update log: %w", err)
+		}
+	}
+	return nil
+}
+
+// add stores update durably and returns its id in the log.
+func (l *UpdateLog) add(update *models.Update) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	id := l.nextID
+	if err := l.write(logRecord{Op: "add", ID: id, Update: update}, true); err != nil {
+		return 0, err
+	}
+	l.nextID++
+
+	seg := l.segments[len(l.segments)-1]
+	seg.pending++
+	l.pending[id] = &logEntry{seg: seg, update: update}
+	return id, nil
+}
+
+// errDeadLettered is returned by start when an update ran out of attempts.
+var errDeadLettered = errors.New("update moved to the dead-letter file")
+
+// start records an attempt at handling id and reports whether it may be
+// made. Once MaxAttempts is used up, the update is dead-lettered instead.
+func (l *UpdateLog) start(id int64) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.pending[id]
+	if !ok {
+		return false, nil
+	}
+	if e.attempts >= l.maxAttempts {
+		if err := l.deadLetter(id, e); err != nil {
+			return false, err
+		}
+		return false, errDeadLettered
+	}
+
+	e.attempts++
+	return true, l.write(logRecord{Op: "start", ID: id}, false)
+}
+
+// exhausted reports whether id has been started MaxAttempts times.
+func (l *UpdateLog) exhausted(id int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.pending[id]
+	return ok && e.attempts >= l.maxAttempts
+}
+
+// finish marks id as handled.
+func (l *UpdateLog) finish(id int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.finishLocked(id)
+}
+
+func (l *UpdateLog) finishLocked(id int64) error {
+	e, ok := l.pending[id]
+	if !ok {
+		return nil
+	}
+	if err := l.write(logRecord{Op: "done", ID: id}, false); err != nil {
+		return err
+	}
+
+	delete(l.pending, id)
+	e.seg.pending--
+	return l.compact()
+}
+
+// deadLetter moves e to the dead-letter file. It must be called with l.mu held.
+func (l *UpdateLog) deadLetter(id int64, e *logEntry) error {
+	file, err := os.OpenFile(filepath.Join(l.dir, deadLetterFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer file.Close()
+
+	data, err := json.Marshal(deadLetter{Update: e.update, Attempts: e.attempts, Time: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync dead-letter file: %w", err)
+	}
+	return l.finishLocked(id)
+}
+
+// compact deletes the leading segments whose updates have all finished.
+// It must be called with l.mu held.
+func (l *UpdateLog) compact() error {
+	for len(l.segments) > 1 && l.segments[0].pending == 0 {
+		if err := os.Remove(l.segments[0].path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove update log segment: %w", err)
+		}
+		l.segments = l.segments[1:]
+	}
+	return nil
+}
+
+// unfinished returns the pending updates by id, and their ids in the order they arrived.
+func (l *UpdateLog) unfinished() ([]int64, map[int64]*models.Update) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ids := make([]int64, 0, len(l.pending))
+	updates := make(map[int64]*models.Update, len(l.pending))
+	for id, e := range l.pending {
+		ids = append(ids, id)
+		updates[id] = e.update
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, updates
+}
+
+// Pending returns the number of updates in the log that haven't finished.
+func (l *UpdateLog) Pending() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.pending)
+}
+
+// Close closes the current segment. Updates that are still pending are
+// replayed when the log is opened again.
+func (l *UpdateLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+
+	seg := l.segments[len(l.segments)-1]
+	if seg.file == nil {
+		return nil
+	}
+	err := seg.file.Close()
+	seg.file = nil
+	return err
+}
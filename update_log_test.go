@@ -0,0 +1,403 @@
+package tgx
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/harshyadavone/tgx/models"
+	"github.com/harshyadavone/tgx/pkg/logger"
+)
+
+func testUpdate(id int, text string) *models.Update {
+	return &models.Update{
+		UpdateId: id,
+		Message:  &models.Message{MessageId: int64(id), Chat: models.Chat{Id: 1}, Text: text},
+	}
+}
+
+func openTestLog(t *testing.T, dir string, opts *UpdateLogOptions) *UpdateLog {
+	t.Helper()
+	l, err := OpenUpdateLog(dir, opts)
+	if err != nil {
+		t.Fatalf("OpenUpdateLog: %v", err)
+	}
+	return l
+}
+
+func mustAdd(t *testing.T, l *UpdateLog, update *models.Update) int64 {
+	t.Helper()
+	id, err := l.add(update)
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	return id
+}
+
+func segments(t *testing.T, dir string) []string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
+
+func readDeadLetters(t *testing.T, dir string) []deadLetter {
+	t.Helper()
+	file, err := os.Open(filepath.Join(dir, deadLetterFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var letters []deadLetter
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var letter deadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &letter); err != nil {
+			t.Fatalf("decoding dead letter: %v", err)
+		}
+		letters = append(letters, letter)
+	}
+	return letters
+}
+
+func TestUpdateLogReopen(t *testing.T) {
+	dir := t.TempDir()
+	l := openTestLog(t, dir, nil)
+
+	done := mustAdd(t, l, testUpdate(1, "done"))
+	started := mustAdd(t, l, testUpdate(2, "started"))
+	mustAdd(t, l, testUpdate(3, "queued"))
+	if _, err := l.start(done); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.finish(done); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.start(started); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	l = openTestLog(t, dir, nil)
+	defer l.Close()
+
+	if got := l.Pending(); got != 2 {
+		t.Fatalf("Pending() = %d after reopening, want 2", got)
+	}
+	ids, updates := l.unfinished()
+	var texts []string
+	for _, id := range ids {
+		texts = append(texts, updates[id].Message.Text)
+	}
+	if want := []string{"started", "queued"}; !slices.Equal(texts, want) {
+		t.Errorf("unfinished updates = %q, want %q", texts, want)
+	}
+	if got := l.pending[started].attempts; got != 1 {
+		t.Errorf("attempts of the started update = %d, want 1", got)
+	}
+
+	// New ids continue after the ones in the log.
+	if id := mustAdd(t, l, testUpdate(4, "new")); id <= ids[len(ids)-1] {
+		t.Errorf("new id %d doesn't follow %v", id, ids)
+	}
+}
+
+func TestUpdateLogTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	l := openTestLog(t, dir, nil)
+	mustAdd(t, l, testUpdate(1, "kept"))
+	l.Close()
+
+	paths := segments(t, dir)
+	file, err := os.OpenFile(paths[len(paths)-1], os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"op":"add","id":2,"upd`)
+	file.Close()
+
+	l = openTestLog(t, dir, nil)
+	defer l.Close()
+	if got := l.Pending(); got != 1 {
+		t.Errorf("Pending() = %d with a torn last record, want 1", got)
+	}
+}
+
+func TestUpdateLogRotationAndCompaction(t *testing.T) {
+	dir := t.TempDir()
+	// Every record is larger than a segment, so each one starts a new segment.
+	l := openTestLog(t, dir, &UpdateLogOptions{SegmentSize: 1})
+
+	var ids []int64
+	for i := 1; i <= 3; i++ {
+		ids = append(ids, mustAdd(t, l, testUpdate(i, "x")))
+	}
+	segmentOf := func(id int64) string {
+		return filepath.Join(dir, fmt.Sprintf("%020d%s", id, segmentExt))
+	}
+	for _, id := range ids {
+		if _, err := os.Stat(segmentOf(id)); err != nil {
+			t.Fatalf("segment of update %d: %v", id, err)
+		}
+	}
+
+	// Finishing a later update can't free the segment of an earlier one.
+	if err := l.finish(ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := segments(t, dir); !slices.Contains(got, segmentOf(ids[0])) || !slices.Contains(got, segmentOf(ids[1])) {
+		t.Fatalf("segments %v lost one of an earlier pending update", got)
+	}
+
+	if err := l.finish(ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	got := segments(t, dir)
+	if slices.Contains(got, segmentOf(ids[0])) || slices.Contains(got, segmentOf(ids[1])) {
+		t.Errorf("segments %v still hold finished updates", got)
+	}
+	if !slices.Contains(got, segmentOf(ids[2])) {
+		t.Errorf("segments %v lost the pending update %d", got, ids[2])
+	}
+
+	if err := l.finish(ids[2]); err != nil {
+		t.Fatal(err)
+	}
+	if got := segments(t, dir); len(got) != 1 {
+		t.Fatalf("segments %v once everything finished, want only the current one", got)
+	}
+	l.Close()
+
+	l = openTestLog(t, dir, &UpdateLogOptions{SegmentSize: 1})
+	defer l.Close()
+	if got := l.Pending(); got != 0 {
+		t.Errorf("Pending() = %d after reopening, want 0", got)
+	}
+	if got := segments(t, dir); len(got) != 1 {
+		t.Errorf("segments %v after reopening, want one", got)
+	}
+}
+
+func TestUpdateLogDeadLetter(t *testing.T) {
+	dir := t.TempDir()
+	l := openTestLog(t, dir, &UpdateLogOptions{MaxAttempts: 2})
+
+	id := mustAdd(t, l, testUpdate(1, "poison"))
+	for attempt := 1; attempt <= 2; attempt++ {
+		if ok, err := l.start(id); !ok || err != nil {
+			t.Fatalf("start attempt %d = %v, %v; want true, nil", attempt, ok, err)
+		}
+	}
+	if !l.exhausted(id) {
+		t.Error("exhausted() = false after MaxAttempts starts")
+	}
+	if ok, err := l.start(id); ok || !errors.Is(err, errDeadLettered) {
+		t.Fatalf("start after MaxAttempts = %v, %v; want false, errDeadLettered", ok, err)
+	}
+	if got := l.Pending(); got != 0 {
+		t.Errorf("Pending() = %d after dead-lettering, want 0", got)
+	}
+	l.Close()
+
+	letters := readDeadLetters(t, dir)
+	if len(letters) != 1 || letters[0].Update.UpdateId != 1 || letters[0].Attempts != 2 {
+		t.Errorf("dead letters = %+v, want update 1 after 2 attempts", letters)
+	}
+}
+
+func TestUpdateLogDeadLetterAfterCrash(t *testing.T) {
+	dir := t.TempDir()
+	l := openTestLog(t, dir, nil)
+	id := mustAdd(t, l, testUpdate(1, "crash"))
+	if _, err := l.start(id); err != nil {
+		t.Fatal(err)
+	}
+	l.Close() // The process died while handling the update.
+
+	l = openTestLog(t, dir, &UpdateLogOptions{MaxAttempts: 1})
+	defer l.Close()
+	if _, err := l.start(id); !errors.Is(err, errDeadLettered) {
+		t.Fatalf("start after a crash = %v, want errDeadLettered", err)
+	}
+	if letters := readDeadLetters(t, dir); len(letters) != 1 {
+		t.Errorf("%d dead letters, want 1", len(letters))
+	}
+}
+
+func TestReplayUpdates(t *testing.T) {
+	dir := t.TempDir()
+	l := openTestLog(t, dir, nil)
+	for i, text := range []string{"first", "second", "third"} {
+		mustAdd(t, l, testUpdate(i+1, text))
+	}
+	l.Close()
+
+	l = openTestLog(t, dir, nil)
+	b := NewBot("1:token", "", logger.NewDefaultLogger(logger.ERROR),
+		WithUpdateLog(l), WithOrderedUpdates(ByChat))
+
+	var mu sync.Mutex
+	var handled []string
+	b.OnMessage("Text", func(ctx *Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, ctx.Text)
+		return nil
+	})
+
+	b.ReplayUpdates()
+	// A new update of the same chat waits for the replayed ones.
+	if err := b.dispatcher.dispatch(context.Background(), testUpdate(4, "new"), false); err != nil {
+		t.Fatal(err)
+	}
+	b.ReplayUpdates() // Only the first call replays.
+
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"first", "second", "third", "new"}; !slices.Equal(handled, want) {
+		t.Errorf("handled %q, want %q", handled, want)
+	}
+
+	l = openTestLog(t, dir, nil)
+	defer l.Close()
+	if got := l.Pending(); got != 0 {
+		t.Errorf("Pending() = %d after replay, want 0", got)
+	}
+}
+
+func TestFailingHandlerIsRetriedThenDeadLettered(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for a retry delay")
+	}
+
+	dir := t.TempDir()
+	l := openTestLog(t, dir, &UpdateLogOptions{MaxAttempts: 2})
+	b := NewBot("1:token", "", logger.NewDefaultLogger(logger.ERROR), WithUpdateLog(l))
+
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	reported := make(map[string]int)
+	b.OnError(func(ctx *Context, err error) {
+		mu.Lock()
+		reported[ctx.Text]++
+		mu.Unlock()
+	})
+	b.OnMessage("Text", func(ctx *Context) error {
+		mu.Lock()
+		calls[ctx.Text]++
+		mu.Unlock()
+		switch ctx.Text {
+		case "error":
+			return errors.New("failed")
+		case "panic":
+			panic("boom")
+		case "blocked":
+			return &BotError{Code: 403, Message: "Forbidden", Err: &APIError{Code: 403, Description: "Forbidden: bot was blocked by the user"}}
+		case "forbidden":
+			return &BotError{Code: 400, Message: "Bad Request", Err: &APIError{Code: 400, Description: "Bad Request: message text is empty"}}
+		}
+		return nil
+	})
+	b.OnCommand("known", func(*Context) error { return nil })
+
+	for i, text := range []string{"error", "panic", "ok", "/unknown", "blocked", "forbidden"} {
+		if err := b.dispatcher.dispatch(context.Background(), testUpdate(i+1, text), false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for l.Pending() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := map[string]int{"error": 2, "panic": 2, "ok": 1, "blocked": 1, "forbidden": 1}
+	for text, n := range want {
+		if calls[text] != n {
+			t.Errorf("%q handled %d times, want %d", text, calls[text], n)
+		}
+	}
+	// Only the last attempt is reported; 403 is already logged by safeExecute.
+	wantReported := map[string]int{"error": 1, "panic": 1, "forbidden": 1}
+	if !maps.Equal(reported, wantReported) {
+		t.Errorf("error handler called %v, want %v", reported, wantReported)
+	}
+
+	var dead []int
+	for _, letter := range readDeadLetters(t, dir) {
+		dead = append(dead, letter.Update.UpdateId)
+	}
+	slices.Sort(dead)
+	if want := []int{1, 2}; !slices.Equal(dead, want) {
+		t.Errorf("dead-lettered updates %v, want %v", dead, want)
+	}
+}
+
+func TestRetryBackoffReleasesWorker(t *testing.T) {
+	l := openTestLog(t, t.TempDir(), &UpdateLogOptions{MaxAttempts: 2})
+	b := NewBot("1:token", "", logger.NewDefaultLogger(logger.ERROR),
+		WithUpdateLog(l), WithWorkerPool(WorkerPool{Workers: 1}), WithOrderedUpdates(ByChat))
+
+	handled := make(chan string, 4)
+	b.OnMessage("Text", func(ctx *Context) error {
+		handled <- ctx.Text
+		if ctx.Text == "fail" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	other := testUpdate(2, "other")
+	other.Message.Chat.Id = 2
+	for _, update := range []*models.Update{testUpdate(1, "fail"), other} {
+		if err := b.dispatcher.dispatch(context.Background(), update, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The only worker handles the other chat while the failed update waits
+	// for its retry.
+	for _, want := range []string{"fail", "other"} {
+		select {
+		case got := <-handled:
+			if got != want {
+				t.Fatalf("handled %q, want %q", got, want)
+			}
+		case <-time.After(minRetryDelay / 2):
+			t.Fatalf("%q not handled during the retry delay", want)
+		}
+	}
+
+	// Shutdown cancels the retry and keeps the update for the next run.
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	l = openTestLog(t, l.dir, nil)
+	defer l.Close()
+	if got := l.Pending(); got != 1 {
+		t.Errorf("Pending() = %d after shutting down during a retry, want 1", got)
+	}
+}
@@ -0,0 +1,285 @@
+package tgx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UploadProgressFunc reports how many bytes of a multipart request have been
+// written so far. total is -1 when the size of an upload isn't known up front.
+type UploadProgressFunc func(sent, total int64)
+
+type uploadProgressKey struct{}
+
+// WithUploadProgress returns a copy of ctx that reports the progress of any
+// upload made with it, so a long upload can update a status message:
+//
+//	ctx = tgx.WithUploadProgress(ctx, func(sent, total int64) { ... })
+//	msg, err := bot.SendVideoCtx(ctx, req)
+//
+// fn is called from the goroutine streaming the request body.
+func WithUploadProgress(ctx context.Context, fn UploadProgressFunc) context.Context {
+	return context.WithValue(ctx, uploadProgressKey{}, fn)
+}
+
+func uploadProgress(ctx context.Context) UploadProgressFunc {
+	fn, _ := ctx.Value(uploadProgressKey{}).(UploadProgressFunc)
+	return fn
+}
+
+// formField is a non-file multipart field, already encoded.
+type formField struct {
+	key   string
+	value string
+}
+
+// formValue encodes a parameter for a multipart form. Strings and scalars are
+// sent as is; everything else is JSON-serialized as the Bot API expects.
+func formValue(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v), nil
+	}
+
+	data, err := json.Marshal(val)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// upload is a file sent as part of a multipart request.
+type upload struct {
+	field  string
+	file   *InputFile
+	size   int64 // -1 when unknown
+	offset int64 // where a seekable Reader started, to rewind it on retries
+	opened bool
+}
+
+func newUpload(field string, file *InputFile) (*upload, error) {
+	u := &upload{field: field, file: file, size: -1}
+
+	switch {
+	case file.Path != "":
+		info, err := os.Stat(file.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file: %w", err)
+		}
+		if info.Mode().IsRegular() {
+			u.size = info.Size()
+		}
+	case file.Data != nil:
+		u.size = int64(len(file.Data))
+	case file.Reader != nil:
+		if s, ok := file.Reader.(io.Seeker); ok {
+			pos, err := s.Seek(0, io.SeekCurrent)
+			if err != nil {
+				break
+			}
+			u.offset = pos
+			if end, err := s.Seek(0, io.SeekEnd); err == nil {
+				u.size = end - pos
+			}
+			if _, err := s.Seek(pos, io.SeekStart); err != nil {
+				return nil, fmt.Errorf("failed to rewind %q: %w", file.fileName(), err)
+			}
+		} else if l, ok := file.Reader.(interface{ Len() int }); ok {
+			u.size = int64(l.Len())
+		}
+	}
+	return u, nil
+}
+
+// open returns the content for one attempt. Readers are rewound on retries,
+// which is only possible when they implement io.Seeker.
+func (u *upload) open() (io.ReadCloser, error) {
+	if u.opened && u.file.Path == "" && u.file.Data == nil {
+		s, ok := u.file.Reader.(io.Seeker)
+		if !ok {
+			return nil, fmt.Errorf("can't resend %q: reader is not seekable", u.file.fileName())
+		}
+		if _, err := s.Seek(u.offset, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to rewind %q: %w", u.file.fileName(), err)
+		}
+	}
+	u.opened = true
+	return u.file.open()
+}
+
+// quoteEscaper escapes file and field names the same way mime/multipart does.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func (u *upload) header() textproto.MIMEHeader {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(u.field), quoteEscaper.Replace(u.file.fileName())))
+	header.Set("Content-Type", u.file.mimeType())
+	return header
+}
+
+// writeMultipart writes the whole form. With contents nil only the framing is
+// written, which is how the Content-Length of the request is worked out.
+func writeMultipart(writer *multipart.Writer, fields []formField, uploads []*upload, contents []io.Reader) error {
+	for i, u := range uploads {
+		part, err := writer.CreatePart(u.header())
+		if err != nil {
+			return fmt.Errorf("failed to create form file: %w", err)
+		}
+		if contents == nil {
+			continue
+		}
+		if _, err := io.Copy(part, contents[i]); err != nil {
+			return fmt.Errorf("failed to write file to form: %w", err)
+		}
+	}
+
+	for _, field := range fields {
+		if err := writer.WriteField(field.key, field.value); err != nil {
+			return fmt.Errorf("failed to write form field %q: %w", field.key, err)
+		}
+	}
+
+	return writer.Close()
+}
+
+// multipartSize returns the exact size of the form, or -1 if any upload has an unknown size.
+func multipartSize(boundary string, fields []formField, uploads []*upload) (int64, error) {
+	var files int64
+	for _, u := range uploads {
+		if u.size < 0 {
+			return -1, nil
+		}
+		files += u.size
+	}
+
+	counter := &countingWriter{w: io.Discard}
+	writer := multipart.NewWriter(counter)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return 0, err
+	}
+	if err := writeMultipart(writer, fields, uploads, nil); err != nil {
+		return 0, err
+	}
+	return counter.n + files, nil
+}
+
+// countingWriter counts the bytes written through it and reports them to progress.
+type countingWriter struct {
+	w        io.Writer
+	n        int64
+	total    int64
+	progress UploadProgressFunc
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	if c.progress != nil && n > 0 {
+		c.progress(c.n, c.total)
+	}
+	return n, err
+}
+
+// makeMultipartReq streams fields and files as multipart/form-data without
+// buffering the files in memory.
+func (b *Bot) makeMultipartReq(ctx context.Context, method string, fields map[string]interface{}, files map[string]*InputFile) (json.RawMessage, error) {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	formFields := make([]formField, 0, len(keys))
+	for _, key := range keys {
+		value, err := formValue(fields[key])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode form field %q: %w", key, err)
+		}
+		formFields = append(formFields, formField{key: key, value: value})
+	}
+
+	fileKeys := make([]string, 0, len(files))
+	for key := range files {
+		fileKeys = append(fileKeys, key)
+	}
+	sort.Strings(fileKeys)
+
+	uploads := make([]*upload, 0, len(fileKeys))
+	for _, key := range fileKeys {
+		u, err := newUpload(key, files[key])
+		if err != nil {
+			return nil, err
+		}
+		b.logger.Debug("Uploading %s as %s (%d bytes)", key, u.file.fileName(), u.size)
+		uploads = append(uploads, u)
+	}
+
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	size, err := multipartSize(boundary, formFields, uploads)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build multipart form: %w", err)
+	}
+	progress := uploadProgress(ctx)
+
+	// done is closed once the writer of the previous attempt has let go of
+	// the uploads, so a retry never rewinds a reader that is still being read.
+	var done chan struct{}
+
+	return b.execute(ctx, method, fields, true, func() (*requestBody, error) {
+		if done != nil {
+			<-done
+		}
+
+		contents := make([]io.Reader, len(uploads))
+		closers := make([]io.Closer, 0, len(uploads))
+		closeAll := func() {
+			for _, c := range closers {
+				c.Close()
+			}
+		}
+
+		for i, u := range uploads {
+			content, err := u.open()
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			contents[i] = content
+			closers = append(closers, content)
+		}
+
+		pr, pw := io.Pipe()
+		counter := &countingWriter{w: pw, total: size, progress: progress}
+		writer := multipart.NewWriter(counter)
+		if err := writer.SetBoundary(boundary); err != nil {
+			closeAll()
+			return nil, err
+		}
+
+		done = make(chan struct{})
+		go func(done chan struct{}) {
+			defer close(done)
+			defer closeAll()
+			pw.CloseWithError(writeMultipart(writer, formFields, uploads, contents))
+		}(done)
+
+		return &requestBody{
+			reader:      pr,
+			contentType: writer.FormDataContentType(),
+			size:        size,
+		}, nil
+	})
+}
@@ -0,0 +1,170 @@
+package tgx
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/harshyadavone/tgx/pkg/logger"
+)
+
+// uploadServer answers the first failures requests with 429 and the rest with
+// a message, recording what every request carried.
+type uploadServer struct {
+	failures int
+
+	mu       sync.Mutex
+	requests []uploadRequest
+}
+
+type uploadRequest struct {
+	contentLength int64
+	received      int
+	document      string
+	chatID        string
+}
+
+func (s *uploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := uploadRequest{contentLength: r.ContentLength, received: len(body)}
+
+	_, params, _ := strings.Cut(r.Header.Get("Content-Type"), "boundary=")
+	form, err := multipart.NewReader(bytes.NewReader(body), params).ReadForm(1 << 20)
+	if err == nil {
+		req.chatID = form.Value["chat_id"][0]
+		if files := form.File["document"]; len(files) == 1 {
+			f, _ := files[0].Open()
+			data, _ := io.ReadAll(f)
+			req.document = string(data)
+		}
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	n := len(s.requests)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if n <= s.failures {
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 0","parameters":{"retry_after":0}}`)
+		return
+	}
+	io.WriteString(w, `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":42,"type":"private"}}}`)
+}
+
+func newUploadBot(t *testing.T, s *uploadServer) *Bot {
+	t.Helper()
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return NewBot("1:token", "", logger.NewDefaultLogger(logger.ERROR),
+		WithAPIURL(srv.URL), WithRetry(nil), WithRateLimits(nil))
+}
+
+func TestUploadRetryRewindsSeekableReader(t *testing.T) {
+	s := &uploadServer{failures: 1}
+	b := newUploadBot(t, s)
+
+	// The upload starts where the reader is, not at the beginning.
+	reader := strings.NewReader("skipped|file content")
+	reader.Seek(int64(len("skipped|")), io.SeekStart)
+
+	_, err := b.SendDocumentCtx(context.Background(), &SendDocumentRequest{
+		BaseMediaRequest: BaseMediaRequest{ChatId: 42},
+		Document:         FileFromReader("notes.txt", reader),
+	})
+	if err != nil {
+		t.Fatalf("SendDocument: %v", err)
+	}
+
+	if len(s.requests) != 2 {
+		t.Fatalf("server got %d requests, want 2", len(s.requests))
+	}
+	for i, req := range s.requests {
+		if req.contentLength != int64(req.received) {
+			t.Errorf("request %d: Content-Length %d, received %d bytes", i, req.contentLength, req.received)
+		}
+		if req.document != "file content" || req.chatID != "42" {
+			t.Errorf("request %d: document %q to chat %q, want %q to 42", i, req.document, req.chatID, "file content")
+		}
+	}
+}
+
+func TestUploadRetryNeedsSeekableReader(t *testing.T) {
+	s := &uploadServer{failures: 1}
+	b := newUploadBot(t, s)
+
+	_, err := b.SendDocumentCtx(context.Background(), &SendDocumentRequest{
+		BaseMediaRequest: BaseMediaRequest{ChatId: 42},
+		Document:         FileFromReader("stream.txt", iotest.OneByteReader(strings.NewReader("streamed"))),
+	})
+	if err == nil || !strings.Contains(err.Error(), "not seekable") {
+		t.Fatalf("retrying a non-seekable upload = %v, want a not seekable error", err)
+	}
+
+	// The size wasn't known up front, so the body was sent chunked.
+	if len(s.requests) != 1 || s.requests[0].contentLength != -1 || s.requests[0].document != "streamed" {
+		t.Errorf("requests = %+v, want one chunked upload of %q", s.requests, "streamed")
+	}
+}
+
+func TestMultipartSize(t *testing.T) {
+	fields := []formField{{key: "chat_id", value: "42"}, {key: "caption", value: "ünïcödé \"quoted\""}}
+	files := []*InputFile{
+		FileFromBytes(`we"ird\name.txt`, []byte("bytes")),
+		FileFromReader("seekable.bin", bytes.NewReader(make([]byte, 1000))),
+	}
+
+	var uploads []*upload
+	for i, file := range files {
+		u, err := newUpload([]string{"document", "thumbnail"}[i], file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		uploads = append(uploads, u)
+	}
+
+	const boundary = "test-boundary"
+	size, err := multipartSize(boundary, fields, uploads)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.SetBoundary(boundary)
+	contents := make([]io.Reader, len(uploads))
+	for i, u := range uploads {
+		content, err := u.open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer content.Close()
+		contents[i] = content
+	}
+	if err := writeMultipart(writer, fields, uploads, contents); err != nil {
+		t.Fatal(err)
+	}
+	if size != int64(buf.Len()) {
+		t.Errorf("multipartSize = %d, form is %d bytes", size, buf.Len())
+	}
+
+	unknown, err := newUpload("document", FileFromReader("pipe", iotest.OneByteReader(strings.NewReader("x"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size, _ := multipartSize(boundary, fields, []*upload{unknown}); size != -1 {
+		t.Errorf("multipartSize with an unknown upload size = %d, want -1", size)
+	}
+}
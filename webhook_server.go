@@ -0,0 +1,210 @@
+package tgx
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxWebhookBody bounds the size of a webhook request accepted by ListenWebhook.
+const DefaultMaxWebhookBody = 1 << 20
+
+// Paths ListenWebhook serves besides the webhook.
+const (
+	healthPath = "/healthz"
+	readyPath  = "/readyz"
+)
+
+// webhookShutdownTimeout bounds how long ListenWebhook waits for the requests
+// in progress once Shutdown is called.
+const webhookShutdownTimeout = 30 * time.Second
+
+// WebhookOptions configures ListenWebhook.
+type WebhookOptions struct {
+	// Path the updates are posted to. It defaults to a path derived from a
+	// hash of the bot token, so it can't be guessed from the public URL.
+	Path string
+
+	// CertFile and KeyFile make the server speak TLS itself.
+	CertFile string
+	KeyFile  string
+	// SelfSigned uploads CertFile to Telegram along with the webhook.
+	SelfSigned bool
+
+	MaxBodySize int64 // Largest accepted request body in bytes, DefaultMaxWebhookBody by default
+
+	// Webhook holds extra setWebhook settings. Its URL is ignored: the
+	// webhook is registered at the bot's webhook URL followed by Path.
+	Webhook *SetWebhookRequest
+	// SkipSetWebhook serves updates without registering the webhook first.
+	SkipSetWebhook bool
+}
+
+// ListenWebhook registers the webhook with Telegram and serves it on addr
+// until Shutdown is called, then returns nil. Besides the webhook path it
+// answers /healthz, which succeeds while the server runs, and /readyz, which
+// succeeds once the webhook is registered and until Shutdown is called; the
+// webhook path can't be either of them. Shutdown waits for ListenWebhook to
+// finish the requests in progress, for up to 30 seconds.
+func (b *Bot) ListenWebhook(addr string, opts *WebhookOptions) error {
+	b.pollers.add()
+	defer b.pollers.done()
+	if b.done.Err() != nil {
+		return &BotError{
+			Code:    http.StatusServiceUnavailable,
+			Message: "Can't listen for webhook requests",
+			Err:     errShuttingDown,
+		}
+	}
+
+	if opts == nil {
+		opts = &WebhookOptions{}
+	}
+	path := opts.Path
+	if path == "" {
+		path = b.webhookPath()
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if path == healthPath || path == readyPath {
+		return &BotError{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid webhook path",
+			Err:     fmt.Errorf("%s is reserved for the health check", path),
+		}
+	}
+	maxBody := opts.MaxBodySize
+	if maxBody <= 0 {
+		maxBody = DefaultMaxWebhookBody
+	}
+
+	// Load the certificate up front: once the webhook is set, Telegram
+	// must find a server that can answer it.
+	var tlsConfig *tls.Config
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return &BotError{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to load the webhook TLS certificate",
+				Err:     err,
+			}
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+
+	var ready atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc(readyPath, func(w http.ResponseWriter, r *http.Request) {
+		if !ready.Load() || b.done.Err() != nil {
+			http.Error(w, "Not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBody)
+		b.HandleWebhook(w, r)
+	})
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return &BotError{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to listen for webhook requests",
+			Err:     err,
+		}
+	}
+
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         tlsConfig,
+	}
+	served := make(chan error, 1)
+	go func() {
+		if tlsConfig != nil {
+			served <- srv.ServeTLS(listener, "", "")
+		} else {
+			served <- srv.Serve(listener)
+		}
+	}()
+	b.logger.Info("Listening for webhook requests on %s", listener.Addr())
+
+	b.ReplayUpdates()
+
+	select {
+	case err := <-served:
+		return err
+	default:
+	}
+
+	if !opts.SkipSetWebhook {
+		if err := b.registerWebhook(path, opts); err != nil {
+			srv.Close()
+			return err
+		}
+	}
+	ready.Store(true)
+
+	select {
+	case err := <-served:
+		return err
+	case <-b.done.Done():
+	}
+
+	// Shutdown has been called: HandleWebhook already turns updates away, so
+	// just let the requests in progress finish.
+	ctx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		srv.Close()
+		return err
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// webhookPath derives the default webhook path from the bot token.
+func (b *Bot) webhookPath() string {
+	sum := sha256.Sum256([]byte(b.token))
+	return "/" + hex.EncodeToString(sum[:])
+}
+
+func (b *Bot) registerWebhook(path string, opts *WebhookOptions) error {
+	var req SetWebhookRequest
+	if opts.Webhook != nil {
+		req = *opts.Webhook
+	}
+	if b.webhookURL == "" {
+		return &BotError{
+			Code:    http.StatusBadRequest,
+			Message: "Can't set webhook",
+			Err:     errors.New("the bot has no webhook URL"),
+		}
+	}
+	req.URL = strings.TrimRight(b.webhookURL, "/") + path
+	if opts.SelfSigned {
+		req.Certificate = FileFromPath(opts.CertFile)
+	}
+
+	if err := b.SetWebhookWithOptsCtx(b.done, &req); err != nil {
+		return err
+	}
+	b.logger.Info("Webhook set")
+	return nil
+}
@@ -0,0 +1,48 @@
+package tgx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/harshyadavone/tgx/pkg/logger"
+)
+
+func TestListenWebhookReservedPaths(t *testing.T) {
+	b := NewBot("1:token", "", logger.NewDefaultLogger(logger.ERROR))
+	for _, path := range []string{"/healthz", "readyz"} {
+		err := b.ListenWebhook("127.0.0.1:0", &WebhookOptions{Path: path, SkipSetWebhook: true})
+		if err == nil {
+			t.Errorf("ListenWebhook with path %q succeeded", path)
+		}
+	}
+}
+
+func TestShutdownWaitsForListenWebhook(t *testing.T) {
+	b := NewBot("1:token", "", logger.NewDefaultLogger(logger.ERROR))
+
+	returned := make(chan error, 1)
+	go func() {
+		returned <- b.ListenWebhook("127.0.0.1:0", &WebhookOptions{SkipSetWebhook: true})
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for b.pollers.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-returned:
+		if err != nil {
+			t.Errorf("ListenWebhook = %v after Shutdown, want nil", err)
+		}
+	default:
+		t.Fatal("Shutdown returned before ListenWebhook")
+	}
+
+	if err := b.ListenWebhook("127.0.0.1:0", &WebhookOptions{SkipSetWebhook: true}); err == nil {
+		t.Error("ListenWebhook succeeded after Shutdown")
+	}
+}